@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+// NewCELEnv creates the CEL environment label and health expressions
+// are compiled and evaluated in.
+//
+// Expressions have access to the matching resources at `resources` and,
+// for health expressions, to the resource being evaluated at
+// `resource`. Besides the CEL extensions the function `parseCert`
+// parses PEM or base64 encoded certificates.
+func NewCELEnv() (*cel.Env, error) {
+	var env *cel.Env
+
+	envOpts := []cel.EnvOption{
+		cel.Variable("resources", cel.DynType),
+		cel.Variable("resource", cel.DynType),
+
+		ext.Bindings(),
+		ext.Encoders(),
+		ext.Lists(),
+		ext.Math(),
+		ext.Protos(),
+		ext.Sets(),
+		ext.Strings(),
+
+		ext.NativeTypes(reflect.TypeFor[*x509.Certificate]()),
+		cel.Function("parseCert",
+			cel.Overload(
+				"parseCert_dyn",
+				[]*cel.Type{cel.DynType},
+				cel.DynType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					var b []byte
+
+					switch v := args[0].Value().(type) {
+					case string:
+						var err error
+
+						b, err = base64.StdEncoding.DecodeString(v)
+						if err != nil {
+							return types.WrapErr(fmt.Errorf("base64 decode failed: %w", err))
+						}
+					case []byte:
+						b = v
+					default:
+						return types.WrapErr(fmt.Errorf("unsupported type for parseCert: %T", v))
+					}
+
+					if block, _ := pem.Decode(b); block != nil {
+						b = block.Bytes
+					}
+
+					cert, err := x509.ParseCertificate(b)
+					if err != nil {
+						return types.WrapErr(fmt.Errorf("parseCertificate failed: %w", err))
+					}
+
+					return env.CELTypeAdapter().NativeToValue(cert)
+				}),
+			),
+		),
+	}
+
+	env, err := cel.NewEnv(envOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	return env, nil
+}
+
+// compileStringExpression compiles the expression and checks that it
+// returns a string.
+func compileStringExpression(env *cel.Env, expression string) error {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return issues.Err()
+	}
+
+	if outputType := ast.OutputType(); !outputType.IsAssignableType(cel.StringType) && outputType != cel.DynType {
+		return fmt.Errorf("must return a string, got %s", outputType)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ClusterPatterns returns all cluster name patterns of the selector.
+func (s NodeSelector) ClusterPatterns() []string {
+	patterns := make([]string, 0, len(s.ClusterNames)+1)
+	if s.ClusterName != "" {
+		patterns = append(patterns, s.ClusterName)
+	}
+
+	return append(patterns, s.ClusterNames...)
+}
+
+// MatchesCluster returns true if the cluster name matches any of the
+// cluster name patterns of the selector.
+func (s NodeSelector) MatchesCluster(clusterName string) bool {
+	for _, pattern := range s.ClusterPatterns() {
+		if MatchClusterName(pattern, clusterName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchClusterName returns true if the cluster name matches the
+// pattern. The pattern may contain `*` to match any sequence of
+// characters and `?` to match a single character.
+// Unlike path.Match the wildcards also match path separators, which
+// are common in cluster names derived from kubeconfig paths.
+func MatchClusterName(pattern, clusterName string) bool {
+	if !strings.ContainsAny(pattern, "*?") {
+		return pattern == clusterName
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\?`, `.`)
+
+	return regexp.MustCompile("^" + expr + "$").MatchString(clusterName)
+}
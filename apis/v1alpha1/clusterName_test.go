@@ -0,0 +1,24 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesCluster(t *testing.T) {
+	t.Parallel()
+
+	selector := NodeSelector{
+		ClusterName:  "./kubeconfig.yaml+kind-prod-*",
+		ClusterNames: []string{"staging.kubeconfig+kind-staging-?", "dev.kubeconfig+kind-dev"},
+	}
+
+	require.True(t, selector.MatchesCluster("./kubeconfig.yaml+kind-prod-eu-1"))
+	require.True(t, selector.MatchesCluster("staging.kubeconfig+kind-staging-1"))
+	require.True(t, selector.MatchesCluster("dev.kubeconfig+kind-dev"))
+
+	require.False(t, selector.MatchesCluster("./kubeconfig.yaml+kind-staging-1"))
+	require.False(t, selector.MatchesCluster("staging.kubeconfig+kind-staging-10"))
+	require.False(t, selector.MatchesCluster("xdev.kubeconfig+kind-dev"))
+}
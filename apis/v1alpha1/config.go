@@ -14,11 +14,33 @@ import (
 type Config struct {
 	metav1.TypeMeta `json:",inline"`
 
+	// Include is a list of further configuration files to load and
+	// merge into this configuration.
+	// Entries can be files, directories or globs and are relative to
+	// the directory of this file.
+	// Merging fails if the same node, edge, template or style is
+	// defined in multiple files.
+	Include []string `json:"include,omitempty"`
+
 	// Style defines base values for dynamic styling of the diagram.
 	Style Style `json:"style,omitzero"`
 
+	// Templates is a map of template names to reusable node
+	// configurations. Nodes referencing a template are expanded during
+	// parsing.
+	Templates map[string]NodeTemplate `json:"templates,omitempty"`
+
 	// Nodes is a map of node names to their configuration.
 	Nodes map[string]Node `json:"nodes,omitempty"`
+
+	// Edges is a map of arbitrary names to the configuration of links
+	// between nodes in the diagram.
+	Edges map[string]Edge `json:"edges,omitempty"`
+
+	// Discovery is a map of arbitrary names to discoveries that add
+	// nodes for resources found by walking the ownership graph of root
+	// resources in the clusters.
+	Discovery map[string]Discovery `json:"discovery,omitempty"`
 }
 
 // Validate validates the Config object.
@@ -34,23 +56,56 @@ func (c *Config) Validate(ctx context.Context) error {
 		c,
 		c,
 	)
+
+	env, err := NewCELEnv()
+	if err != nil {
+		return err
+	}
+
+	fieldErrors = append(fieldErrors, c.validate(env)...)
+
 	if len(fieldErrors) > 0 {
 		return fieldErrors.ToAggregate()
 	}
-	// TODO custom validation for e.g. node selectors
+
 	return nil
 }
 
 // Style defines styling options for the diagram.
 type Style struct {
 	// Status defines styles for different resource statuses.
+	// Besides overriding the styles of the builtin statuses this can
+	// define styles for arbitrary statuses returned by health
+	// expressions. Statuses without a style are styled like unknown.
 	Status map[ResourceStatus]string `json:"status,omitempty"`
+
+	// Precedence is the order of statuses from highest to lowest
+	// precedence. When the resources of a node have different statuses
+	// the status with the highest precedence is shown.
+	// Builtin statuses that are not listed follow the listed statuses
+	// in their default order, other statuses that are not listed take
+	// precedence over all listed statuses.
+	// Defaults to failed, degraded, unknown, terminating, pending,
+	// healthy, absent.
+	Precedence []ResourceStatus `json:"precedence,omitempty"`
+
+	// EdgeStatus defines styles for different resource statuses of
+	// edges. The styles are applied with linkStyle.
+	EdgeStatus map[ResourceStatus]string `json:"edgeStatus,omitempty"`
 }
 
 // Node represents a node in the diagram.
 type Node struct {
+	// Template is the name of a template to expand into this node.
+	// Nodes using a template must not set a selector. Health and label
+	// override the values of the template when set.
+	Template string `json:"template,omitempty"`
+
+	// Parameters are the values for the parameters of the template.
+	Parameters map[string]string `json:"parameters,omitempty"`
+
 	// Selector defines how to select the resources for this node.
-	Selector NodeSelector `json:"selector"`
+	Selector NodeSelector `json:"selector,omitzero"`
 
 	// Health defines how to determine the health of the node.
 	Health Health `json:"health,omitzero"`
@@ -61,11 +116,53 @@ type Node struct {
 	Label string `json:"label,omitempty"`
 }
 
+// NodeTemplate is a reusable node configuration.
+type NodeTemplate struct {
+	// Parameters are the names of the parameters of the template.
+	// All parameters must be set by nodes using the template.
+	// Parameters are referenced in the node as `${name}` in any string
+	// value.
+	Parameters []string `json:"parameters,omitempty"`
+
+	// Node is the node configuration to expand.
+	Node Node `json:"node"`
+}
+
+// Edge represents a link between two nodes in the diagram.
+type Edge struct {
+	// From is the ID of the node the link starts at.
+	//+k8s:required
+	From string `json:"from"`
+
+	// To is the ID of the node the link ends at.
+	//+k8s:required
+	To string `json:"to"`
+
+	// Selector defines how to select the resources for this edge.
+	Selector NodeSelector `json:"selector"`
+
+	// Health defines how to determine the health of the edge.
+	Health Health `json:"health,omitzero"`
+
+	// Label is an optional label to display on the link, replacing the
+	// label in the diagram.
+	// This is a CEL expression.
+	// The input is a list of all matching resources at `.resources`.
+	Label string `json:"label,omitempty"`
+}
+
 // NodeSelector defines how to select resources in a cluster.
 type NodeSelector struct {
 	// ClusterName is the name of the cluster to select resources from.
-	//+k8s:required
-	ClusterName string `json:"clusterName"`
+	// The name may contain the wildcards `*` to match any sequence of
+	// characters and `?` to match a single character to select
+	// resources from all matching clusters.
+	// Either ClusterName or ClusterNames must be set.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ClusterNames is a list of cluster names to select resources from.
+	// The names may contain wildcards like ClusterName.
+	ClusterNames []string `json:"clusterNames,omitempty"`
 
 	// GVK is the GroupVersionKind of the resources to select.
 	GVK schema.GroupVersionKind `json:"gvk"`
@@ -78,6 +175,16 @@ type NodeSelector struct {
 	// LabelSelector is the label selector to select resources.
 	LabelSelector metav1.LabelSelector `json:"labelSelector,omitzero"`
 
+	// FieldSelector is a field selector to select resources, e.g.
+	// `spec.nodeName=node1,status.phase!=Running`.
+	// Any field of the resource can be selected. The selector is
+	// evaluated by mkl and not passed to the API server, as the
+	// informers of a kind are shared between all nodes. All resources
+	// of the kind are still watched and cached, so a field selector
+	// does not reduce the load on the API server or the memory usage
+	// of mkl.
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
 	// If set, select resources owned by the specified owner.
 	// This is still bound by the GVR and Namespace fields.
 	Owner OwnerReference `json:"owner,omitzero"`
@@ -89,15 +196,102 @@ type OwnerReference struct {
 	GVK schema.GroupVersionKind `json:"gvk,omitzero"`
 	// Name is the name of the owner resource.
 	Name string `json:"name,omitempty"`
+
+	// Depth is the number of ownership levels to walk up to find the
+	// owner, e.g. 2 to select the Pods of a Deployment through their
+	// ReplicaSets.
+	// Intermediate owners are resolved through the cluster cache.
+	// Defaults to 1, which only considers the direct owners.
+	Depth int `json:"depth,omitempty"`
+
+	// AllOwners considers all owner references instead of only the
+	// controller owner reference.
+	AllOwners bool `json:"allOwners,omitempty"`
 }
 
 // Health defines how to determine the health of a resource.
+// Unless an expression is set resources that are being deleted are
+// terminating and resources in the phase Failed are failed regardless
+// of the other options.
 type Health struct {
 	// WhenPresent indicates if the resource is healthy when present.
 	// This is the default when no other option is set.
 	WhenPresent bool `json:"whenPresent,omitempty"`
 
 	// ConditionType is the condition type to check for health.
-	// If set, the resource is healthy when the condition of this type is True.
+	// If set, the resource is healthy when the condition of this type is True,
+	// pending when it is False and unknown when it is Unknown.
 	ConditionType string `json:"conditionType,omitempty"`
+
+	// Expression is a CEL expression returning the status of a
+	// resource as a string, e.g. "healthy" or "pending".
+	// The expression is evaluated once for each matching resource with
+	// the resource at `resource` and all matching resources at
+	// `resources`.
+	Expression string `json:"expression,omitempty"`
+
+	// Aggregation defines how the statuses of all matching resources
+	// are combined into the status of the node.
+	// Defaults to the worst status.
+	Aggregation Aggregation `json:"aggregation,omitzero"`
+}
+
+// AggregationPolicy is a policy to combine the statuses of multiple
+// resources.
+type AggregationPolicy string
+
+const (
+	// AggregationWorst uses the status with the highest precedence
+	// according to Style.Precedence.
+	AggregationWorst AggregationPolicy = "worst"
+	// AggregationBest uses the status with the lowest precedence
+	// according to Style.Precedence.
+	AggregationBest AggregationPolicy = "best"
+	// AggregationAll is healthy when all resources are healthy.
+	AggregationAll AggregationPolicy = "all"
+	// AggregationAny is healthy when at least one resource is healthy.
+	AggregationAny AggregationPolicy = "any"
+	// AggregationAtLeast is healthy when at least Aggregation.Count
+	// resources are healthy.
+	AggregationAtLeast AggregationPolicy = "atLeast"
+	// AggregationPercentage is healthy when at least
+	// Aggregation.Percentage percent of the resources are healthy.
+	AggregationPercentage AggregationPolicy = "percentage"
+)
+
+// Aggregation defines how the statuses of multiple resources are
+// combined.
+// If the policy is not fulfilled the non-healthy status with the
+// highest precedence is used.
+type Aggregation struct {
+	// Policy is the aggregation policy.
+	Policy AggregationPolicy `json:"policy,omitempty"`
+
+	// Count is the minimum number of healthy resources for the
+	// atLeast policy.
+	Count int `json:"count,omitempty"`
+
+	// Percentage is the minimum percentage of healthy resources for the
+	// percentage policy.
+	Percentage int `json:"percentage,omitempty"`
+}
+
+// Discovery discovers nodes by walking the ownership graph from root
+// resources.
+// The root resources and all resources owned by them, directly or
+// transitively, become nodes. The discovered nodes are updated as
+// resources are created or deleted.
+type Discovery struct {
+	// Root selects the resources to start the discovery from.
+	Root NodeSelector `json:"root"`
+
+	// Kinds are the GroupVersionKinds of owned resources to discover.
+	// Owned resources can only be found by listing resources, so
+	// only resources of these kinds are discovered.
+	Kinds []schema.GroupVersionKind `json:"kinds"`
+
+	// Depth is the maximum number of ownership levels to walk from the
+	// root resources.
+	// Defaults to 3, e.g. Deployment -> ReplicaSet -> Pod.
+	Depth int `json:"depth,omitempty"`
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -45,4 +46,116 @@ func TestValidate(t *testing.T) {
 		},
 	}
 	require.Error(t, config.Validate(t.Context()))
+
+	config.Nodes["node"] = Node{
+		Selector: NodeSelector{
+			ClusterNames: []string{"cluster-*"},
+			GVK:          schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+		},
+	}
+	require.NoError(t, config.Validate(t.Context()))
+
+	config = &Config{
+		Nodes: map[string]Node{
+			"node": {
+				Selector: NodeSelector{
+					ClusterName: "cluster",
+				},
+				Health: Health{
+					Aggregation: Aggregation{
+						Policy: AggregationPercentage,
+					},
+				},
+			},
+		},
+	}
+	require.ErrorContains(t, config.Validate(t.Context()), "nodes[node].health.aggregation.percentage")
+}
+
+func TestValidateSemantics(t *testing.T) {
+	t.Parallel()
+
+	selector := NodeSelector{
+		ClusterName: "cluster",
+		GVK:         schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+	}
+
+	cases := map[string]struct {
+		config   Config
+		expected []string
+	}{
+		"valid": {
+			config: Config{
+				Nodes: map[string]Node{
+					"my-node_1": {
+						Selector: selector,
+						Health:   Health{Expression: `resource.status.phase == "Running" ? "healthy" : "pending"`},
+						Label:    `"pods: " + string(size(resources))`,
+					},
+				},
+			},
+		},
+		"invalid expressions": {
+			config: Config{
+				Nodes: map[string]Node{
+					"node": {
+						Selector: selector,
+						Health:   Health{Expression: `resource.status.phase ==`},
+						Label:    `size(resources)`,
+					},
+				},
+			},
+			expected: []string{"nodes[node].health.expression", "nodes[node].label: Invalid value: \"size(resources)\": must return a string"},
+		},
+		"invalid selectors": {
+			config: Config{
+				Nodes: map[string]Node{
+					"node": {
+						Selector: NodeSelector{
+							ClusterName: "cluster",
+							GVK:         schema.GroupVersionKind{Group: "apps"},
+							LabelSelector: metav1.LabelSelector{
+								MatchLabels: map[string]string{"in valid": "value"},
+							},
+							Owner: OwnerReference{Name: "owner"},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"nodes[node].selector.gvk.version", "nodes[node].selector.gvk.kind",
+				"nodes[node].selector.labelSelector",
+				"nodes[node].selector.owner.gvk.kind",
+			},
+		},
+		"invalid node IDs": {
+			config: Config{
+				Nodes: map[string]Node{
+					"my node": {Selector: selector},
+					"end":     {Selector: selector},
+					"node-":   {Selector: selector},
+				},
+				Edges: map[string]Edge{
+					"edge": {From: "a b", To: "node", Selector: selector, Label: `1`},
+				},
+			},
+			expected: []string{"nodes[my node]", "nodes[end]", "nodes[node-]", "edges[edge].from", "edges[edge].label"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.config.Validate(t.Context())
+			if len(tc.expected) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			for _, expected := range tc.expected {
+				require.ErrorContains(t, err, expected)
+			}
+		})
+	}
 }
@@ -0,0 +1,232 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Load loads the configuration from the given path and merges it with
+// all included configurations.
+// The path can be a file, a directory or a glob. Directories load all
+// .yaml and .yml files in the directory.
+// Includes are resolved relative to the directory of the including
+// file and can be files, directories or globs as well.
+//
+// Besides the configuration Load returns all paths that were loaded,
+// including directories and globs, to watch for changes.
+func Load(path string) (*Config, []string, error) {
+	l := NewLoader()
+
+	if err := l.LoadPath(path); err != nil {
+		return nil, nil, err
+	}
+
+	return l.Config()
+}
+
+// Loader loads configurations from multiple sources and merges them.
+//
+// +k8s:deepcopy-gen=false
+type Loader struct {
+	config *Config
+	// sources tracks which file defined a key to report conflicts
+	sources map[string]string
+	// loaded tracks loaded files to prevent include cycles
+	loaded map[string]bool
+	paths  []string
+}
+
+// NewLoader returns a new Loader.
+func NewLoader() *Loader {
+	return &Loader{
+		config:  &Config{},
+		sources: map[string]string{},
+		loaded:  map[string]bool{},
+	}
+}
+
+// Config returns the merged configuration with expanded templates and
+// all paths that were loaded.
+func (l *Loader) Config() (*Config, []string, error) {
+	if err := l.config.ExpandTemplates(); err != nil {
+		return nil, l.paths, fmt.Errorf("failed to expand templates: %w", err)
+	}
+
+	return l.config, l.paths, nil
+}
+
+// LoadPath loads the configuration from a file, directory or glob, see
+// Load.
+func (l *Loader) LoadPath(path string) error {
+	files, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+
+	l.paths = append(l.paths, path)
+
+	for _, file := range files {
+		if err := l.loadFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadEmbedded loads a configuration embedded under the key "mkl" in
+// the YAML data, e.g. from the frontmatter of a mermaid diagram.
+// The source is used in error messages and includes are resolved
+// relative to the directory of the source.
+func (l *Loader) LoadEmbedded(source string, data []byte) error {
+	embedded := struct {
+		MKL Config `json:"mkl"`
+	}{}
+
+	if err := yaml.UnmarshalStrict(data, &embedded); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+
+	return l.loadConfig(source, &embedded.MKL)
+}
+
+func (l *Loader) loadFile(file string) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	if l.loaded[abs] {
+		return nil
+	}
+
+	l.loaded[abs] = true
+
+	if !slices.Contains(l.paths, file) {
+		l.paths = append(l.paths, file)
+	}
+
+	data, err := os.ReadFile(file) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return l.loadConfig(file, config)
+}
+
+func (l *Loader) loadConfig(source string, config *Config) error {
+	if err := l.merge(source, config); err != nil {
+		return err
+	}
+
+	for _, include := range config.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(source), include)
+		}
+
+		if err := l.LoadPath(include); err != nil {
+			return fmt.Errorf("failed to load include %s of %s: %w", include, source, err)
+		}
+	}
+
+	return nil
+}
+
+func (l *Loader) merge(file string, config *Config) error {
+	var errs []string
+
+	mergeMap(l, &errs, file, "style.status", &l.config.Style.Status, config.Style.Status)
+	mergeMap(l, &errs, file, "style.edgeStatus", &l.config.Style.EdgeStatus, config.Style.EdgeStatus)
+	mergeMap(l, &errs, file, "templates", &l.config.Templates, config.Templates)
+	mergeMap(l, &errs, file, "nodes", &l.config.Nodes, config.Nodes)
+	mergeMap(l, &errs, file, "edges", &l.config.Edges, config.Edges)
+	mergeMap(l, &errs, file, "discovery", &l.config.Discovery, config.Discovery)
+
+	if len(config.Style.Precedence) > 0 {
+		if source, ok := l.sources["style.precedence"]; ok {
+			errs = append(errs, fmt.Sprintf("style.precedence is defined in both %s and %s", source, file))
+		} else {
+			l.sources["style.precedence"] = file
+			l.config.Style.Precedence = config.Style.Precedence
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("conflicting configuration: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func mergeMap[K ~string, V any](l *Loader, errs *[]string, file, field string, dst *map[K]V, src map[K]V) {
+	for _, key := range slices.Sorted(maps.Keys(src)) {
+		sourceKey := field + "[" + string(key) + "]"
+		if source, ok := l.sources[sourceKey]; ok {
+			*errs = append(*errs, fmt.Sprintf("%s is defined in both %s and %s", sourceKey, source, file))
+			continue
+		}
+
+		l.sources[sourceKey] = file
+
+		if *dst == nil {
+			*dst = map[K]V{}
+		}
+
+		(*dst)[key] = src[key]
+	}
+}
+
+// resolvePath returns the files for a file, directory or glob path.
+func resolvePath(path string) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		files, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %s: %w", path, err)
+		}
+
+		if len(files) == 0 {
+			return nil, fmt.Errorf("glob %s does not match any files", path)
+		}
+
+		return files, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if ext := filepath.Ext(entry.Name()); ext == ".yaml" || ext == ".yml" {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
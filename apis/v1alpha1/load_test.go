@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"mkl.yaml": `
+include:
+  - templates/*.yaml
+  - nodes
+`,
+		"templates/secret.yaml": `
+templates:
+  secret:
+    parameters: [name]
+    node:
+      selector:
+        clusterName: cluster
+        gvk:
+          version: v1
+          kind: Secret
+        name: ${name}
+`,
+		"nodes/a.yaml": `
+nodes:
+  a:
+    template: secret
+    parameters:
+      name: a
+`,
+		"nodes/b.yml": `
+include: [../mkl.yaml]
+nodes:
+  b:
+    template: secret
+    parameters:
+      name: b
+edges:
+  ab:
+    from: a
+    to: b
+`,
+		"nodes/ignored.txt": `not yaml`,
+	})
+
+	config, paths, err := Load(filepath.Join(dir, "mkl.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "a", config.Nodes["a"].Selector.Name)
+	require.Equal(t, "b", config.Nodes["b"].Selector.Name)
+	require.Contains(t, config.Edges, "ab")
+	require.Contains(t, paths, filepath.Join(dir, "nodes"))
+	require.Contains(t, paths, filepath.Join(dir, "templates/*.yaml"))
+	require.Contains(t, paths, filepath.Join(dir, "nodes", "b.yml"))
+
+	config, _, err = Load(filepath.Join(dir, "nodes"))
+	require.NoError(t, err, "templates are included through the include cycle")
+	require.Len(t, config.Nodes, 2)
+}
+
+func TestLoadConflict(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": `
+nodes:
+  node:
+    label: '"a"'
+`,
+		"b.yaml": `
+nodes:
+  node:
+    label: '"b"'
+`,
+	})
+
+	_, _, err := Load(filepath.Join(dir, "*.yaml"))
+	require.ErrorContains(t, err, "nodes[node] is defined in both "+filepath.Join(dir, "a.yaml")+" and "+filepath.Join(dir, "b.yaml"))
+}
+
+func TestLoaderEmbedded(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"mkl.yaml": `
+nodes:
+  a:
+    label: '"a"'
+`,
+	})
+
+	l := NewLoader()
+	require.NoError(t, l.LoadEmbedded(filepath.Join(dir, "diagram.mermaid"), []byte(`
+mkl:
+  include: [mkl.yaml]
+  nodes:
+    b:
+      label: '"b"'
+`)))
+
+	config, paths, err := l.Config()
+	require.NoError(t, err)
+	require.Len(t, config.Nodes, 2)
+	require.Equal(t, []string{filepath.Join(dir, "mkl.yaml")}, paths)
+
+	require.ErrorContains(t, l.LoadEmbedded("diagram.mermaid", []byte("mkl:\n  nodes:\n    a: {}\n")), "nodes[a] is defined in both")
+}
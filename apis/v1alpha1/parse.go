@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -28,12 +29,16 @@ func ParseFile(filename string) (*Config, error) {
 	return config, f.Close()
 }
 
-// Parse parses the given YAML data.
+// Parse parses the given YAML data and expands node templates.
 func Parse(data []byte) (*Config, error) {
 	config := &Config{}
 	if err := yaml.UnmarshalStrict(data, config); err != nil {
 		return nil, err
 	}
 
+	if err := config.ExpandTemplates(); err != nil {
+		return nil, fmt.Errorf("failed to expand templates: %w", err)
+	}
+
 	return config, nil
 }
@@ -1,6 +1,10 @@
 package v1alpha1
 
+import "slices"
+
 // ResourceStatus represents the status of a resource in a cluster.
+// Besides the statuses defined here arbitrary statuses can be returned
+// by health expressions and styled through Style.Status.
 type ResourceStatus string
 
 const (
@@ -10,8 +14,30 @@ const (
 	ResourcePending ResourceStatus = "pending"
 	// ResourceHealthy indicates that the resource is present and healthy.
 	ResourceHealthy ResourceStatus = "healthy"
+	// ResourceDegraded indicates that the resource is present and
+	// working but not fully functional.
+	ResourceDegraded ResourceStatus = "degraded"
+	// ResourceFailed indicates that the resource is present and failed.
+	ResourceFailed ResourceStatus = "failed"
+	// ResourceTerminating indicates that the resource is being deleted.
+	ResourceTerminating ResourceStatus = "terminating"
+	// ResourceUnknown indicates that the status of the resource could
+	// not be determined.
+	ResourceUnknown ResourceStatus = "unknown"
 )
 
+// DefaultPrecedence is the order of statuses from highest to lowest
+// precedence used when Style.Precedence is not set.
+var DefaultPrecedence = []ResourceStatus{
+	ResourceFailed,
+	ResourceDegraded,
+	ResourceUnknown,
+	ResourceTerminating,
+	ResourcePending,
+	ResourceHealthy,
+	ResourceAbsent,
+}
+
 // String returns the string representation of the ResourceStatus.
 func (rs ResourceStatus) String() string {
 	return string(rs)
@@ -26,7 +52,92 @@ func (rs ResourceStatus) DefaultStyle() string {
 		return "stroke:yellow,stroke-width:4px,fill:lightyellow"
 	case ResourceHealthy:
 		return "stroke:green,stroke-width:4px,fill:lightgreen"
+	case ResourceDegraded:
+		return "stroke:orange,stroke-width:4px,fill:moccasin"
+	case ResourceFailed:
+		return "stroke:red,stroke-width:4px,fill:mistyrose"
+	case ResourceTerminating:
+		return "stroke:grey,stroke-width:4px,stroke-dasharray:5 5"
+	case ResourceUnknown:
+		return "stroke:purple,stroke-width:4px,fill:lavender"
 	default:
 		return ""
 	}
 }
+
+// DefaultEdgeStyle returns the default style for edges with the
+// ResourceStatus.
+func (rs ResourceStatus) DefaultEdgeStyle() string {
+	switch rs {
+	case ResourceAbsent:
+		return "stroke:grey,stroke-width:2px"
+	case ResourcePending:
+		return "stroke:yellow,stroke-width:4px"
+	case ResourceHealthy:
+		return "stroke:green,stroke-width:4px"
+	case ResourceDegraded:
+		return "stroke:orange,stroke-width:4px"
+	case ResourceFailed:
+		return "stroke:red,stroke-width:4px"
+	case ResourceTerminating:
+		return "stroke:grey,stroke-width:4px,stroke-dasharray:5 5"
+	case ResourceUnknown:
+		return "stroke:purple,stroke-width:4px"
+	default:
+		return ""
+	}
+}
+
+// MostSignificant returns the status with the highest precedence
+// according to the given precedence list. If precedence is empty
+// DefaultPrecedence is used.
+// Builtin statuses missing from the precedence list follow the listed
+// statuses in the order of DefaultPrecedence. Other statuses not
+// present in the precedence list take precedence over all listed
+// statuses. Returns ResourceAbsent if statuses is empty.
+func MostSignificant(precedence []ResourceStatus, statuses ...ResourceStatus) ResourceStatus {
+	return significant(precedence, statuses, func(rank, retRank int) bool { return rank < retRank })
+}
+
+// LeastSignificant returns the status with the lowest precedence
+// according to the given precedence list.
+// See MostSignificant for details.
+func LeastSignificant(precedence []ResourceStatus, statuses ...ResourceStatus) ResourceStatus {
+	return significant(precedence, statuses, func(rank, retRank int) bool { return rank > retRank })
+}
+
+func significant(precedence, statuses []ResourceStatus, better func(rank, retRank int) bool) ResourceStatus {
+	precedence = completePrecedence(precedence)
+
+	ret := ResourceAbsent
+	retRank := len(precedence)
+
+	for i, status := range statuses {
+		rank := slices.Index(precedence, status)
+		if i == 0 || better(rank, retRank) {
+			ret = status
+			retRank = rank
+		}
+	}
+
+	return ret
+}
+
+// completePrecedence appends the builtin statuses missing from the
+// precedence list in the order of DefaultPrecedence, so a partial list
+// like [failed, degraded] does not rank healthy above failed.
+func completePrecedence(precedence []ResourceStatus) []ResourceStatus {
+	if len(precedence) == 0 {
+		return DefaultPrecedence
+	}
+
+	ret := slices.Clone(precedence)
+
+	for _, status := range DefaultPrecedence {
+		if !slices.Contains(ret, status) {
+			ret = append(ret, status)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,263 @@
+package v1alpha1
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// configSource is the source of the configuration types to extract
+// the descriptions of the JSON schema from their doc comments.
+//
+//go:embed config.go
+var configSource []byte
+
+// JSONSchema returns the JSON schema of the configuration, e.g. for
+// editors to validate and complete configuration files.
+func JSONSchema() ([]byte, error) {
+	docs, err := parseDocs(configSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse doc comments: %w", err)
+	}
+
+	g := &schemaGenerator{
+		docs: docs,
+		defs: map[string]any{},
+	}
+
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "mermaid-kube-live configuration",
+		"$ref":    g.schema(reflect.TypeFor[Config]())["$ref"],
+		"$defs":   g.defs,
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// typeDocs are the doc comments and enum values of a type.
+type typeDocs struct {
+	doc    string
+	fields map[string]fieldDocs
+	enum   []string
+}
+
+type fieldDocs struct {
+	doc      string
+	required bool
+}
+
+// parseDocs parses the doc comments of the types and fields and the
+// constants of string types in the source.
+func parseDocs(src []byte) (map[string]*typeDocs, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := map[string]*typeDocs{}
+	get := func(name string) *typeDocs {
+		if docs[name] == nil {
+			docs[name] = &typeDocs{fields: map[string]fieldDocs{}}
+		}
+		return docs[name]
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				t := get(spec.Name.Name)
+				t.doc = commentText(genDecl.Doc)
+
+				structType, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						t.fields[name.Name] = fieldDocs{
+							doc:      commentText(field.Doc),
+							required: field.Doc != nil && strings.Contains(field.Doc.Text(), "+k8s:required"),
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				typeIdent, ok := spec.Type.(*ast.Ident)
+				if !ok || genDecl.Tok != token.CONST {
+					continue
+				}
+
+				for _, value := range spec.Values {
+					if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						if s, err := strconv.Unquote(lit.Value); err == nil {
+							get(typeIdent.Name).enum = append(get(typeIdent.Name).enum, s)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return docs, nil
+}
+
+// commentText returns the text of the comment without markers.
+func commentText(comment *ast.CommentGroup) string {
+	if comment == nil {
+		return ""
+	}
+
+	var lines []string
+
+	for line := range strings.SplitSeq(comment.Text(), "\n") {
+		if strings.HasPrefix(line, "+") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+type schemaGenerator struct {
+	docs map[string]*typeDocs
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		ret := map[string]any{"type": "string"}
+		if docs := g.docs[t.Name()]; docs != nil && len(docs.enum) > 0 {
+			ret["enum"] = docs.enum
+		}
+
+		return ret
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema adds the struct as definition and returns a reference to
+// it.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	ref := map[string]any{"$ref": "#/$defs/" + t.Name()}
+
+	if _, ok := g.defs[t.Name()]; ok {
+		return ref
+	}
+
+	def := map[string]any{
+		"type": "object",
+		// unknown fields are rejected when parsing
+		"additionalProperties": false,
+	}
+	// register before recursing to support recursive types
+	g.defs[t.Name()] = def
+
+	docs := g.docs[t.Name()]
+	if docs != nil && docs.doc != "" {
+		def["description"] = docs.doc
+	}
+
+	properties := map[string]any{}
+
+	var required []string
+
+	g.addProperties(t, docs, properties, &required)
+
+	def["properties"] = properties
+	if len(required) > 0 {
+		def["required"] = required
+	}
+
+	return ref
+}
+
+func (g *schemaGenerator) addProperties(t reflect.Type, docs *typeDocs, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if opts == "inline" || (field.Anonymous && name == "") {
+			g.addProperties(field.Type, g.docs[field.Type.Name()], properties, required)
+			continue
+		}
+
+		if name == "" {
+			// encoding/json matches untagged fields case-insensitively
+			r, size := utf8.DecodeRuneInString(field.Name)
+			name = string(unicode.ToLower(r)) + field.Name[size:]
+		}
+
+		property := g.schema(field.Type)
+
+		if docs != nil {
+			if fieldDocs, ok := docs.fields[field.Name]; ok {
+				if fieldDocs.doc != "" {
+					if _, isRef := property["$ref"]; isRef {
+						// siblings of $ref are allowed since draft 2019-09
+						property = map[string]any{"$ref": property["$ref"]}
+					}
+
+					property["description"] = fieldDocs.doc
+				}
+
+				if fieldDocs.required {
+					*required = append(*required, name)
+				}
+			}
+		}
+
+		properties[name] = property
+	}
+}
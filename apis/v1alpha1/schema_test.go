@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := JSONSchema()
+	require.NoError(t, err)
+
+	generated, err := os.ReadFile("config.schema.json")
+	require.NoError(t, err)
+	require.Equal(t, string(generated), string(schema), "config.schema.json is outdated, run make codegen")
+
+	parsed := struct {
+		Defs map[string]struct {
+			Description string                     `json:"description"`
+			Properties  map[string]json.RawMessage `json:"properties"`
+			Required    []string                   `json:"required"`
+		} `json:"$defs"`
+	}{}
+	require.NoError(t, json.Unmarshal(schema, &parsed))
+
+	require.Contains(t, parsed.Defs["NodeSelector"].Description, "NodeSelector defines how to select resources")
+	require.Contains(t, parsed.Defs["NodeSelector"].Properties, "clusterName")
+	require.Contains(t, parsed.Defs["Config"].Properties, "apiVersion", "inlined TypeMeta")
+	require.Contains(t, parsed.Defs["GroupVersionKind"].Properties, "kind", "untagged fields")
+	require.Equal(t, []string{"from", "to"}, parsed.Defs["Edge"].Required)
+	require.JSONEq(t, `{
+		"description": "Policy is the aggregation policy.",
+		"enum": ["worst", "best", "all", "any", "atLeast", "percentage"],
+		"type": "string"
+	}`, string(parsed.Defs["Aggregation"].Properties["policy"]))
+}
@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	field "k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// templateParameter matches template parameters like ${cluster}.
+var templateParameter = regexp.MustCompile(`\$\{([a-zA-Z0-9_-]+)\}`)
+
+// ExpandTemplates replaces all nodes referencing a template with the
+// expanded template.
+func (c *Config) ExpandTemplates() error {
+	var errs field.ErrorList
+
+	for _, nodeName := range slices.Sorted(maps.Keys(c.Nodes)) {
+		node := c.Nodes[nodeName]
+		if node.Template == "" {
+			continue
+		}
+
+		fldPath := field.NewPath("nodes").Key(nodeName)
+
+		expanded, nodeErrs := c.expandTemplate(fldPath, node)
+		if len(nodeErrs) > 0 {
+			errs = append(errs, nodeErrs...)
+			continue
+		}
+
+		c.Nodes[nodeName] = expanded
+	}
+
+	return errs.ToAggregate()
+}
+
+func (c *Config) expandTemplate(fldPath *field.Path, node Node) (Node, field.ErrorList) {
+	template, ok := c.Templates[node.Template]
+	if !ok {
+		return Node{}, field.ErrorList{field.NotFound(fldPath.Child("template"), node.Template)}
+	}
+
+	var errs field.ErrorList
+
+	if !node.Selector.isZero() {
+		errs = append(errs, field.Forbidden(fldPath.Child("selector"), "must not be set when using a template"))
+	}
+
+	for _, param := range template.Parameters {
+		if _, ok := node.Parameters[param]; !ok {
+			errs = append(errs, field.Required(fldPath.Child("parameters").Key(param), fmt.Sprintf("required by template %q", node.Template)))
+		}
+	}
+
+	for _, param := range slices.Sorted(maps.Keys(node.Parameters)) {
+		if !slices.Contains(template.Parameters, param) {
+			errs = append(errs, field.NotSupported(fldPath.Child("parameters").Key(param), param, template.Parameters))
+		}
+	}
+
+	if len(errs) > 0 {
+		return Node{}, errs
+	}
+
+	raw, err := json.Marshal(template.Node)
+	if err != nil {
+		return Node{}, field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	var unknown []string
+
+	raw = templateParameter.ReplaceAllFunc(raw, func(match []byte) []byte {
+		name := string(templateParameter.FindSubmatch(match)[1])
+
+		value, ok := node.Parameters[name]
+		if !ok {
+			unknown = append(unknown, name)
+			return match
+		}
+
+		// escape the value for use in a JSON string
+		escaped, _ := json.Marshal(value) //nolint:errchkjson // strings always marshal
+		return escaped[1 : len(escaped)-1]
+	})
+
+	if len(unknown) > 0 {
+		return Node{}, field.ErrorList{field.Invalid(
+			field.NewPath("templates").Key(node.Template),
+			strings.Join(unknown, ", "),
+			"references undeclared parameters",
+		)}
+	}
+
+	expanded := Node{}
+	if err := json.Unmarshal(raw, &expanded); err != nil {
+		return Node{}, field.ErrorList{field.Invalid(fldPath.Child("parameters"), node.Parameters, fmt.Sprintf("failed to expand template: %v", err))}
+	}
+
+	expanded.Template = node.Template
+	expanded.Parameters = node.Parameters
+
+	if node.Label != "" {
+		expanded.Label = node.Label
+	}
+
+	if node.Health != (Health{}) {
+		expanded.Health = node.Health
+	}
+
+	return expanded, nil
+}
+
+func (s NodeSelector) isZero() bool {
+	return s.ClusterName == "" && len(s.ClusterNames) == 0 &&
+		s.GVK.Empty() && s.Name == "" && s.Namespace == "" &&
+		len(s.LabelSelector.MatchLabels) == 0 && len(s.LabelSelector.MatchExpressions) == 0 &&
+		s.FieldSelector == "" && s.Owner == (OwnerReference{})
+}
@@ -0,0 +1,82 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	t.Parallel()
+
+	config, err := Parse([]byte(`
+templates:
+  secret:
+    parameters: [cluster, name]
+    node:
+      selector:
+        clusterName: ./kubeconfig.yaml+${cluster}
+        namespace: default
+        gvk:
+          version: v1
+          kind: Secret
+        name: ${name}
+      label: '"${name}"'
+nodes:
+  cluster1secret:
+    template: secret
+    parameters:
+      cluster: kind-cluster1
+      name: our-first-secret
+  cluster2secret:
+    template: secret
+    parameters:
+      cluster: kind-cluster2
+      name: our-"quoted"-secret
+    label: '"overridden"'
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, "./kubeconfig.yaml+kind-cluster1", config.Nodes["cluster1secret"].Selector.ClusterName)
+	require.Equal(t, "our-first-secret", config.Nodes["cluster1secret"].Selector.Name)
+	require.Equal(t, "Secret", config.Nodes["cluster1secret"].Selector.GVK.Kind)
+	require.Equal(t, `"our-first-secret"`, config.Nodes["cluster1secret"].Label)
+
+	require.Equal(t, `our-"quoted"-secret`, config.Nodes["cluster2secret"].Selector.Name)
+	require.Equal(t, `"overridden"`, config.Nodes["cluster2secret"].Label)
+
+	require.NoError(t, config.Validate(t.Context()))
+}
+
+func TestExpandTemplatesErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte(`
+templates:
+  secret:
+    parameters: [cluster]
+    node:
+      selector:
+        clusterName: ${cluster}
+        name: ${name}
+nodes:
+  missing:
+    template: secret
+  unknown:
+    template: secret
+    parameters:
+      cluster: a
+      namespace: b
+  undeclared:
+    template: secret
+    parameters:
+      cluster: a
+  notemplate:
+    template: does-not-exist
+`))
+	require.Error(t, err)
+	require.ErrorContains(t, err, "nodes[missing].parameters[cluster]")
+	require.ErrorContains(t, err, "nodes[unknown].parameters[namespace]")
+	require.ErrorContains(t, err, "templates[secret]")
+	require.ErrorContains(t, err, "nodes[notemplate].template")
+}
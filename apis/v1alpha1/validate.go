@@ -1,8 +1,212 @@
 package v1alpha1
 
 import (
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fields "k8s.io/apimachinery/pkg/fields"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	field "k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // Only for the registration in the generated validation code.
 var localSchemeBuilder = &runtime.SchemeBuilder{}
+
+// validate validates the semantics of the configuration that cannot
+// be expressed with the generated validation, e.g. CEL expressions.
+func (c *Config) validate(env *cel.Env) field.ErrorList {
+	var errs field.ErrorList
+
+	for _, nodeName := range slices.Sorted(maps.Keys(c.Nodes)) {
+		fldPath := field.NewPath("nodes").Key(nodeName)
+		if err := validateNodeID(nodeName); err != "" {
+			errs = append(errs, field.Invalid(fldPath, nodeName, err))
+		}
+
+		errs = append(errs, c.Nodes[nodeName].validate(env, fldPath)...)
+	}
+
+	for _, edgeName := range slices.Sorted(maps.Keys(c.Edges)) {
+		errs = append(errs, c.Edges[edgeName].validate(env, field.NewPath("edges").Key(edgeName))...)
+	}
+
+	for _, discoveryName := range slices.Sorted(maps.Keys(c.Discovery)) {
+		errs = append(errs, c.Discovery[discoveryName].validate(field.NewPath("discovery").Key(discoveryName))...)
+	}
+
+	return errs
+}
+
+func (n Node) validate(env *cel.Env, fldPath *field.Path) field.ErrorList {
+	errs := n.Selector.validate(fldPath.Child("selector"))
+	errs = append(errs, n.Health.validate(env, fldPath.Child("health"))...)
+	errs = append(errs, validateLabel(env, n.Label, fldPath.Child("label"))...)
+
+	return errs
+}
+
+func (e Edge) validate(env *cel.Env, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for _, ref := range []struct {
+		name, id string
+	}{{"from", e.From}, {"to", e.To}} {
+		// empty references are reported by the generated validation
+		if ref.id == "" {
+			continue
+		}
+
+		if err := validateNodeID(ref.id); err != "" {
+			errs = append(errs, field.Invalid(fldPath.Child(ref.name), ref.id, err))
+		}
+	}
+
+	errs = append(errs, e.Selector.validate(fldPath.Child("selector"))...)
+	errs = append(errs, e.Health.validate(env, fldPath.Child("health"))...)
+	errs = append(errs, validateLabel(env, e.Label, fldPath.Child("label"))...)
+
+	return errs
+}
+
+func (h Health) validate(env *cel.Env, fldPath *field.Path) field.ErrorList {
+	errs := h.Aggregation.validate(fldPath.Child("aggregation"))
+
+	if h.Expression != "" {
+		if err := compileStringExpression(env, h.Expression); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("expression"), h.Expression, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func validateLabel(env *cel.Env, label string, fldPath *field.Path) field.ErrorList {
+	if label == "" {
+		return nil
+	}
+
+	if err := compileStringExpression(env, label); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, label, err.Error())}
+	}
+
+	return nil
+}
+
+// nodeIDPattern matches node IDs that can be referenced in mermaid
+// flowcharts without quoting.
+var nodeIDPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+(-[\p{L}\p{N}_]+)*$`)
+
+// reservedNodeIDs are keywords that cannot be used as node IDs in
+// mermaid flowcharts.
+var reservedNodeIDs = []string{
+	"end", "flowchart", "graph", "subgraph", "direction",
+	"style", "classDef", "class", "click", "linkStyle",
+}
+
+// validateNodeID returns a description of the problem if the ID is not
+// a valid mermaid node ID.
+func validateNodeID(id string) string {
+	if !nodeIDPattern.MatchString(id) {
+		return "must be a valid mermaid node ID consisting of letters, digits, underscores and inner dashes"
+	}
+
+	if slices.Contains(reservedNodeIDs, id) {
+		return "must not be a mermaid keyword"
+	}
+
+	return ""
+}
+
+func validateGVK(gvk schema.GroupVersionKind, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if gvk.Version == "" {
+		errs = append(errs, field.Required(fldPath.Child("version"), ""))
+	}
+
+	if gvk.Kind == "" {
+		errs = append(errs, field.Required(fldPath.Child("kind"), ""))
+	}
+
+	return errs
+}
+
+func (d Discovery) validate(fldPath *field.Path) field.ErrorList {
+	errs := d.Root.validate(fldPath.Child("root"))
+
+	if len(d.Kinds) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("kinds"), "at least one kind to discover is required"))
+	}
+
+	for i, gvk := range d.Kinds {
+		errs = append(errs, validateGVK(gvk, fldPath.Child("kinds").Index(i))...)
+	}
+
+	if d.Depth < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("depth"), d.Depth, "must not be negative"))
+	}
+
+	return errs
+}
+
+func (s NodeSelector) validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s.ClusterName == "" && len(s.ClusterNames) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("clusterName"), "either clusterName or clusterNames must be set"))
+	}
+
+	for i, name := range s.ClusterNames {
+		if name == "" {
+			errs = append(errs, field.Required(fldPath.Child("clusterNames").Index(i), ""))
+		}
+	}
+
+	errs = append(errs, validateGVK(s.GVK, fldPath.Child("gvk"))...)
+
+	if _, err := metav1.LabelSelectorAsSelector(&s.LabelSelector); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("labelSelector"), s.LabelSelector, err.Error()))
+	}
+
+	if s.Owner.Name != "" {
+		errs = append(errs, validateGVK(s.Owner.GVK, fldPath.Child("owner", "gvk"))...)
+	}
+
+	if s.Owner.Depth < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("owner", "depth"), s.Owner.Depth, "must not be negative"))
+	}
+
+	if s.FieldSelector != "" {
+		if _, err := fields.ParseSelector(s.FieldSelector); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("fieldSelector"), s.FieldSelector, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func (a Aggregation) validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	switch a.Policy {
+	case "", AggregationWorst, AggregationBest, AggregationAll, AggregationAny:
+	case AggregationAtLeast:
+		if a.Count < 1 {
+			errs = append(errs, field.Invalid(fldPath.Child("count"), a.Count, "must be at least 1 for the atLeast policy"))
+		}
+	case AggregationPercentage:
+		if a.Percentage < 1 || a.Percentage > 100 {
+			errs = append(errs, field.Invalid(fldPath.Child("percentage"), a.Percentage, "must be between 1 and 100 for the percentage policy"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("policy"), a.Policy, []AggregationPolicy{
+			AggregationWorst, AggregationBest, AggregationAll, AggregationAny, AggregationAtLeast, AggregationPercentage,
+		}))
+	}
+
+	return errs
+}
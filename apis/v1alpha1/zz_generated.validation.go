@@ -37,47 +37,50 @@ func RegisterValidations(scheme *runtime.Scheme) error {
 // to declarative validation rules in the API schema.
 func Validate_Config(ctx context.Context, op operation.Operation, fldPath *field.Path, obj, oldObj *Config) (errs field.ErrorList) {
 	// field Config.TypeMeta has no validation
+	// field Config.Include has no validation
 	// field Config.Style has no validation
+	// field Config.Templates has no validation
+	// field Config.Nodes has no validation
 
-	// field Config.Nodes
+	// field Config.Edges
 	errs = append(errs,
-		func(fldPath *field.Path, obj, oldObj map[string]Node, oldValueCorrelated bool) (errs field.ErrorList) {
+		func(fldPath *field.Path, obj, oldObj map[string]Edge, oldValueCorrelated bool) (errs field.ErrorList) {
 			// don't revalidate unchanged data
 			if oldValueCorrelated && op.Type == operation.Update && equality.Semantic.DeepEqual(obj, oldObj) {
 				return nil
 			}
 			// iterate the map and call the value type's validation function
-			errs = append(errs, validate.EachMapVal(ctx, op, fldPath, obj, oldObj, validate.SemanticDeepEqual, Validate_Node)...)
+			errs = append(errs, validate.EachMapVal(ctx, op, fldPath, obj, oldObj, validate.SemanticDeepEqual, Validate_Edge)...)
 			return
-		}(fldPath.Child("nodes"), obj.Nodes, safe.Field(oldObj, func(oldObj *Config) map[string]Node { return oldObj.Nodes }), oldObj != nil)...)
+		}(fldPath.Child("edges"), obj.Edges, safe.Field(oldObj, func(oldObj *Config) map[string]Edge { return oldObj.Edges }), oldObj != nil)...)
 
+	// field Config.Discovery has no validation
 	return errs
 }
 
-// Validate_Node validates an instance of Node according
+// Validate_Edge validates an instance of Edge according
 // to declarative validation rules in the API schema.
-func Validate_Node(ctx context.Context, op operation.Operation, fldPath *field.Path, obj, oldObj *Node) (errs field.ErrorList) {
-	// field Node.Selector
+func Validate_Edge(ctx context.Context, op operation.Operation, fldPath *field.Path, obj, oldObj *Edge) (errs field.ErrorList) {
+	// field Edge.From
 	errs = append(errs,
-		func(fldPath *field.Path, obj, oldObj *NodeSelector, oldValueCorrelated bool) (errs field.ErrorList) {
+		func(fldPath *field.Path, obj, oldObj *string, oldValueCorrelated bool) (errs field.ErrorList) {
 			// don't revalidate unchanged data
-			if oldValueCorrelated && op.Type == operation.Update && equality.Semantic.DeepEqual(obj, oldObj) {
+			if oldValueCorrelated && op.Type == operation.Update && (obj == oldObj || (obj != nil && oldObj != nil && *obj == *oldObj)) {
 				return nil
 			}
-			// call the type's validation function
-			errs = append(errs, Validate_NodeSelector(ctx, op, fldPath, obj, oldObj)...)
+			// call field-attached validations
+			earlyReturn := false
+			if e := validate.RequiredValue(ctx, op, fldPath, obj, oldObj); len(e) != 0 {
+				errs = append(errs, e...)
+				earlyReturn = true
+			}
+			if earlyReturn {
+				return // do not proceed
+			}
 			return
-		}(fldPath.Child("selector"), &obj.Selector, safe.Field(oldObj, func(oldObj *Node) *NodeSelector { return &oldObj.Selector }), oldObj != nil)...)
+		}(fldPath.Child("from"), &obj.From, safe.Field(oldObj, func(oldObj *Edge) *string { return &oldObj.From }), oldObj != nil)...)
 
-	// field Node.Health has no validation
-	// field Node.Label has no validation
-	return errs
-}
-
-// Validate_NodeSelector validates an instance of NodeSelector according
-// to declarative validation rules in the API schema.
-func Validate_NodeSelector(ctx context.Context, op operation.Operation, fldPath *field.Path, obj, oldObj *NodeSelector) (errs field.ErrorList) {
-	// field NodeSelector.ClusterName
+	// field Edge.To
 	errs = append(errs,
 		func(fldPath *field.Path, obj, oldObj *string, oldValueCorrelated bool) (errs field.ErrorList) {
 			// don't revalidate unchanged data
@@ -94,12 +97,10 @@ func Validate_NodeSelector(ctx context.Context, op operation.Operation, fldPath
 				return // do not proceed
 			}
 			return
-		}(fldPath.Child("clusterName"), &obj.ClusterName, safe.Field(oldObj, func(oldObj *NodeSelector) *string { return &oldObj.ClusterName }), oldObj != nil)...)
+		}(fldPath.Child("to"), &obj.To, safe.Field(oldObj, func(oldObj *Edge) *string { return &oldObj.To }), oldObj != nil)...)
 
-	// field NodeSelector.GVK has no validation
-	// field NodeSelector.Name has no validation
-	// field NodeSelector.Namespace has no validation
-	// field NodeSelector.LabelSelector has no validation
-	// field NodeSelector.Owner has no validation
+	// field Edge.Selector has no validation
+	// field Edge.Health has no validation
+	// field Edge.Label has no validation
 	return errs
 }
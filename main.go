@@ -2,43 +2,115 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/configtest"
+	"github.com/ntnn/mermaid-kube-live/pkg/manifests"
 	"github.com/ntnn/mermaid-kube-live/pkg/mkl"
+	"github.com/ntnn/mermaid-kube-live/pkg/recording"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	mctrl "sigs.k8s.io/multicluster-runtime"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
 	"sigs.k8s.io/multicluster-runtime/providers/file"
 )
 
+const usage = `Usage: mkl <command> [flags]
+
+Commands:
+  serve     Serve the live diagram (default)
+  validate  Validate the configuration and diagram without connecting to clusters
+  render    Render the styled diagram once and write it to stdout or a file
+  explain   Explain the status of a node
+  wait      Wait until nodes reach a status
+  test      Test health and label rules against fixture resources
+  schema    Print the JSON schema of the configuration
+
+Run 'mkl <command> -h' for the flags of a command.
+`
+
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func run() error {
-	opts := &mkl.Options{}
-	fs := opts.FlagSet()
+func run(args []string) error {
+	if len(args) == 0 {
+		return runServe(args)
+	}
 
-	fDebug := fs.Bool("debug", false, "Enable debug logging")
-	fKubeconfig := fs.String("kubeconfig", "", "Comma-separated list of kubeconfigs (default: $HOME/.kube/config)")
+	switch args[0] {
+	case "serve":
+		return runServe(args[1:])
+	case "validate":
+		return runValidate(args[1:])
+	case "render":
+		return runRender(args[1:])
+	case "explain":
+		return runExplain(args[1:])
+	case "wait":
+		return runWait(args[1:])
+	case "test":
+		return runTest(args[1:])
+	case "schema":
+		return runSchema()
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	default:
+		// Without a command mkl serves the diagram to stay compatible
+		// with invocations that only pass flags.
+		if strings.HasPrefix(args[0], "-") {
+			return runServe(args)
+		}
 
-	if err := fs.Parse(os.Args[1:]); err != nil {
-		return fmt.Errorf("error parsing flags: %w", err)
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// commonFlags are the flags shared by all commands connecting to
+// clusters.
+type commonFlags struct {
+	debug      *bool
+	kubeconfig *string
+	manifests  *string
+	replay     *string
+	speed      *float64
+}
+
+func newFlagSet(name string, opts *mkl.Options) (*flag.FlagSet, *commonFlags) {
+	fs := opts.FlagSet()
+	fs.Init(name, flag.ExitOnError)
+
+	return fs, &commonFlags{
+		debug:      fs.Bool("debug", false, "Enable debug logging"),
+		kubeconfig: fs.String("kubeconfig", "", "Comma-separated list of kubeconfigs (default: $HOME/.kube/config)"),
+		manifests:  fs.String("manifests", "", "Directory of YAML manifests to use instead of clusters, each file or subdirectory is a cluster"),
+		replay:     fs.String("replay", "", "Recording to replay instead of using clusters, see -record"),
+		speed:      fs.Float64("replay-speed", 1, "Factor to speed up the replay by"),
 	}
+}
 
+// setup sets up logging and the provider and returns the context to
+// run with.
+func (c *commonFlags) setup(opts *mkl.Options) (context.Context, error) {
 	// Not pretty but the klog flags are a bit much.
-	if *fDebug {
+	if *c.debug {
 		klogFs := flag.NewFlagSet("klog", flag.ExitOnError)
 		klog.InitFlags(klogFs)
 
 		if err := klogFs.Set("v", "6"); err != nil {
-			return fmt.Errorf("error setting klog verbosity: %w", err)
+			return nil, fmt.Errorf("error setting klog verbosity: %w", err)
 		}
 	}
 
@@ -47,14 +119,57 @@ func run() error {
 	ctx := klog.NewContext(mctrl.SetupSignalHandler(), logger)
 	opts.Logger = logger
 
-	provider, err := file.New(file.Options{
-		KubeconfigFiles: parseKubeconfigPaths(*fKubeconfig),
-	})
+	provider, err := c.provider()
 	if err != nil {
-		return fmt.Errorf("error setting up provider: %w", err)
+		return nil, fmt.Errorf("error setting up provider: %w", err)
 	}
 	opts.Provider = provider
 
+	return ctx, nil
+}
+
+func (c *commonFlags) provider() (multicluster.Provider, error) {
+	if *c.replay != "" {
+		return recording.NewReplayProvider(recording.ReplayOptions{
+			Path:  *c.replay,
+			Speed: *c.speed,
+		})
+	}
+
+	if *c.manifests != "" {
+		return manifests.New(manifests.Options{
+			Path: *c.manifests,
+		})
+	}
+
+	return file.New(file.Options{
+		KubeconfigFiles: parseKubeconfigPaths(*c.kubeconfig),
+	})
+}
+
+func runServe(args []string) error {
+	opts := &mkl.Options{}
+	fs, common := newFlagSet("serve", opts)
+	fDiagrams := fs.String("diagrams", "", "File listing named diagrams with their config and diagram to serve instead of -config and -diagram")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	if *fDiagrams != "" {
+		diagrams, err := mkl.LoadDiagrams(*fDiagrams)
+		if err != nil {
+			return err
+		}
+
+		opts.Diagrams = diagrams
+	}
+
+	ctx, err := common.setup(opts)
+	if err != nil {
+		return err
+	}
+
 	instance, err := mkl.New(opts)
 	if err != nil {
 		return fmt.Errorf("error creating MKL: %w", err)
@@ -63,6 +178,206 @@ func run() error {
 	return instance.Run(ctx)
 }
 
+func runValidate(args []string) error {
+	opts := &mkl.Options{}
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.StringVar(&opts.ConfigPath, "config", "", "Configuration file, directory or glob")
+	fs.StringVar(&opts.DiagramPath, "diagram", "", "Diagram file, generated from the configuration if not set")
+	fStrict := fs.Bool("strict", false, "Fail on warnings")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	warnings, err := mkl.Validate(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+
+	if *fStrict && len(warnings) > 0 {
+		return fmt.Errorf("found %d warnings", len(warnings))
+	}
+
+	return nil
+}
+
+func runRender(args []string) error {
+	opts := &mkl.Options{}
+	fs, common := newFlagSet("render", opts)
+	fTimeout := fs.Duration("timeout", time.Minute, "Maximum time to wait for the resources of all nodes to be observed")
+	fOutput := fs.String("output", "", "File to write the diagram to (default: stdout)")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	ctx, err := common.setup(opts)
+	if err != nil {
+		return err
+	}
+
+	instance, err := mkl.New(opts)
+	if err != nil {
+		return fmt.Errorf("error creating MKL: %w", err)
+	}
+
+	diagram, err := instance.Render(ctx, *fTimeout)
+	if err != nil {
+		return fmt.Errorf("error rendering diagram: %w", err)
+	}
+
+	if *fOutput != "" {
+		return os.WriteFile(*fOutput, diagram, 0o644) //nolint:gosec
+	}
+
+	_, err = os.Stdout.Write(diagram)
+	return err
+}
+
+func runExplain(args []string) error {
+	opts := &mkl.Options{}
+	fs, common := newFlagSet("explain", opts)
+	fTimeout := fs.Duration("timeout", time.Minute, "Maximum time to wait for the resources of all nodes to be observed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mkl explain [flags] <node>")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("explain requires exactly one node")
+	}
+
+	ctx, err := common.setup(opts)
+	if err != nil {
+		return err
+	}
+
+	instance, err := mkl.New(opts)
+	if err != nil {
+		return fmt.Errorf("error creating MKL: %w", err)
+	}
+
+	explanation, err := instance.Explain(ctx, *fTimeout, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error explaining node: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, explanation.String())
+
+	return nil
+}
+
+func runWait(args []string) error {
+	opts := &mkl.Options{}
+	fs, common := newFlagSet("wait", opts)
+	fFor := fs.String("for", string(mklv1alpha1.ResourceHealthy), "Status to wait for")
+	fTimeout := fs.Duration("timeout", 10*time.Minute, "Maximum time to wait for the nodes to reach the status")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mkl wait [flags] [node...]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	ctx, err := common.setup(opts)
+	if err != nil {
+		return err
+	}
+
+	instance, err := mkl.New(opts)
+	if err != nil {
+		return fmt.Errorf("error creating MKL: %w", err)
+	}
+
+	return instance.Wait(ctx, *fTimeout, mklv1alpha1.ResourceStatus(*fFor), fs.Args(), os.Stderr)
+}
+
+func runTest(args []string) error {
+	opts := &mkl.Options{}
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.StringVar(&opts.ConfigPath, "config", "", "Configuration file, directory or glob")
+	fs.StringVar(&opts.DiagramPath, "diagram", "", "Diagram file with embedded configuration")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mkl test [flags] <test file>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("test requires at least one test file")
+	}
+
+	ctx := context.Background()
+
+	config, err := mkl.LoadConfig(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	passed, failed := 0, 0
+
+	for _, path := range fs.Args() {
+		cases, err := configtest.Load(path)
+		if err != nil {
+			return err
+		}
+
+		results, err := configtest.Run(ctx, config, cases)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if result.Passed() {
+				passed++
+				fmt.Fprintf(os.Stdout, "ok   %s: %s\n", path, result.Name)
+
+				continue
+			}
+
+			failed++
+			fmt.Fprintf(os.Stdout, "FAIL %s: %s\n", path, result.Name)
+
+			for _, failure := range result.Failures {
+				fmt.Fprintf(os.Stdout, "       %s\n", failure)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "%d passed, %d failed\n", passed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d tests failed", failed)
+	}
+
+	return nil
+}
+
+func runSchema() error {
+	schema, err := mklv1alpha1.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("error generating schema: %w", err)
+	}
+
+	_, err = os.Stdout.Write(schema)
+	return err
+}
+
 func parseKubeconfigPaths(kubeconfigs string) []string {
 	if kubeconfigs != "" {
 		return strings.Split(kubeconfigs, ",")
@@ -0,0 +1,187 @@
+// Package configtest tests the health and label rules of a
+// configuration against fixture resources without clusters.
+package configtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/styler"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+)
+
+// Suite is a file of test cases.
+type Suite struct {
+	// Tests are the test cases.
+	Tests []Case `json:"tests"`
+}
+
+// Case is a test case for a node or an edge.
+type Case struct {
+	// Name is the name of the test case.
+	Name string `json:"name,omitempty"`
+
+	// Node is the name of the node to test.
+	// Either Node or Edge must be set.
+	Node string `json:"node,omitempty"`
+	// Edge is the name of the edge to test.
+	Edge string `json:"edge,omitempty"`
+
+	// Resources are the resources matched by the node.
+	Resources []map[string]any `json:"resources,omitempty"`
+
+	// Status is the expected status. It is not checked if empty.
+	Status mklv1alpha1.ResourceStatus `json:"status,omitempty"`
+	// Label is the expected label. It is not checked if not set.
+	Label *string `json:"label,omitempty"`
+	// Error is a substring of the expected error of the health
+	// expression or label. If empty no error is expected.
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the result of a test case.
+type Result struct {
+	// Name is the name of the test case.
+	Name string
+	// Failures describe the differences to the expectations of the
+	// test case. The test case passed if there are none.
+	Failures []string
+}
+
+// Passed returns true if the test case passed.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Load loads the test cases from a file.
+func Load(path string) ([]Case, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var suite Suite
+	if err := yaml.UnmarshalStrict(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return suite.Tests, nil
+}
+
+// Run runs the test cases against the configuration.
+func Run(ctx context.Context, config *mklv1alpha1.Config, cases []Case) ([]Result, error) {
+	celEnv, err := styler.NewCELEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	results := make([]Result, len(cases))
+	for i, c := range cases {
+		results[i] = run(ctx, celEnv, config, i, c)
+	}
+
+	return results, nil
+}
+
+func run(ctx context.Context, celEnv *styler.CELEnv, config *mklv1alpha1.Config, index int, c Case) Result {
+	result := Result{Name: c.Name}
+	if result.Name == "" {
+		result.Name = fmt.Sprintf("#%d", index+1)
+	}
+
+	resources, err := toUnstructured(c.Resources)
+	if err != nil {
+		result.Failures = append(result.Failures, err.Error())
+		return result
+	}
+
+	var evaluation styler.Evaluation
+
+	switch {
+	case c.Node != "" && c.Edge != "":
+		result.Failures = append(result.Failures, "only one of node and edge must be set")
+		return result
+	case c.Node != "":
+		node, ok := config.Nodes[c.Node]
+		if !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("node %q is not configured", c.Node))
+			return result
+		}
+
+		evaluation, err = styler.Evaluate(ctx, celEnv, config.Style, node, resources)
+	case c.Edge != "":
+		edge, ok := config.Edges[c.Edge]
+		if !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("edge %q is not configured", c.Edge))
+			return result
+		}
+
+		evaluation, err = styler.EvaluateEdge(ctx, celEnv, config.Style, edge, resources)
+	default:
+		result.Failures = append(result.Failures, "either node or edge must be set")
+		return result
+	}
+
+	result.Failures = append(result.Failures, compare(c, evaluation, err)...)
+
+	return result
+}
+
+// toUnstructured converts the resources of a test case.
+// The resources are decoded again so numbers are integers like in
+// resources read from clusters instead of floats.
+func toUnstructured(resources []map[string]any) ([]unstructured.Unstructured, error) {
+	ret := make([]unstructured.Unstructured, len(resources))
+
+	for i, resource := range resources {
+		data, err := json.Marshal(resource)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource %d: %w", i+1, err)
+		}
+
+		obj := map[string]any{}
+		if err := utiljson.Unmarshal(data, &obj); err != nil {
+			return nil, fmt.Errorf("invalid resource %d: %w", i+1, err)
+		}
+
+		ret[i] = unstructured.Unstructured{Object: obj}
+	}
+
+	return ret, nil
+}
+
+// compare returns the differences between the expectations of the test
+// case and the evaluation.
+func compare(c Case, evaluation styler.Evaluation, err error) []string {
+	var failures []string
+
+	switch {
+	case c.Error == "" && err != nil:
+		failures = append(failures, fmt.Sprintf("unexpected error: %v", err))
+	case c.Error != "" && err == nil:
+		failures = append(failures, fmt.Sprintf("error: expected error containing %q, got none", c.Error))
+	case c.Error != "" && !strings.Contains(err.Error(), c.Error):
+		failures = append(failures, fmt.Sprintf("error: expected error containing %q, got %q", c.Error, err.Error()))
+	}
+
+	if c.Status != "" && c.Status != evaluation.Status {
+		failures = append(failures, fmt.Sprintf("status: expected %q, got %q", c.Status, evaluation.Status))
+	}
+
+	if c.Label != nil {
+		switch {
+		case evaluation.Label == nil:
+			failures = append(failures, fmt.Sprintf("label: expected %q, got no label", *c.Label))
+		case *c.Label != *evaluation.Label:
+			failures = append(failures, fmt.Sprintf("label: expected %q, got %q", *c.Label, *evaluation.Label))
+		}
+	}
+
+	return failures
+}
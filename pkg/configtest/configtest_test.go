@@ -0,0 +1,104 @@
+package configtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	config := &mklv1alpha1.Config{
+		Nodes: map[string]mklv1alpha1.Node{
+			"deployment": {
+				Health: mklv1alpha1.Health{
+					Expression: `resource.status.readyReplicas >= 2 ? "healthy" : "pending"`,
+				},
+				Label: `"ready: " + string(resources[0].status.readyReplicas)`,
+			},
+			// passes validation as resources are dynamically typed
+			"replicas": {
+				Label: `resources[0].spec.replicas`,
+			},
+		},
+		Edges: map[string]mklv1alpha1.Edge{
+			"edge": {Label: `"edge"`},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "tests.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tests:
+  - name: ready
+    node: deployment
+    resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata: {name: app}
+        status: {readyReplicas: 2}
+    status: healthy
+    label: "ready: 2"
+  - name: wrong expectations
+    node: deployment
+    resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata: {name: app}
+        status: {readyReplicas: 1}
+    status: healthy
+    label: "ready: 2"
+  - name: expected error
+    node: deployment
+    resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata: {name: app}
+    error: readyReplicas
+  - name: edge
+    edge: edge
+    label: edge
+  - name: unknown node
+    node: missing
+  - name: non-string label
+    node: replicas
+    resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata: {name: app}
+        spec: {replicas: 2}
+    label: "2"
+  - name: expected non-string label error
+    node: replicas
+    resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata: {name: app}
+        spec: {replicas: 2}
+    error: must return a string
+`), 0o600))
+
+	cases, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cases, 7)
+
+	results, err := Run(t.Context(), config, cases)
+	require.NoError(t, err)
+	require.Len(t, results, 7)
+
+	require.True(t, results[0].Passed(), results[0].Failures)
+	require.Equal(t, []string{
+		`status: expected "healthy", got "pending"`,
+		`label: expected "ready: 2", got "ready: 1"`,
+	}, results[1].Failures)
+	require.True(t, results[2].Passed(), results[2].Failures)
+	require.True(t, results[3].Passed(), results[3].Failures)
+	require.Equal(t, []string{`node "missing" is not configured`}, results[4].Failures)
+	require.Len(t, results[5].Failures, 2)
+	require.Contains(t, results[5].Failures[0], "unexpected error: CEL expression resources[0].spec.replicas must return a string, got int64")
+	require.Equal(t, "label: expected \"2\", got no label", results[5].Failures[1])
+	require.True(t, results[6].Passed(), results[6].Failures)
+}
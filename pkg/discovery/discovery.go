@@ -0,0 +1,445 @@
+// Package discovery discovers nodes by walking the ownership graph of
+// root resources in the clusters.
+package discovery
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	mctrl "sigs.k8s.io/multicluster-runtime"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// defaultDepth is the default number of ownership levels to walk.
+const defaultDepth = 3
+
+// Discoverer discovers nodes by walking the ownership graph of root
+// resources.
+type Discoverer struct {
+	Logger logr.Logger
+	mp     *multiplexer.Multiplexer
+	// name is the name of the Discoverer in the multiplexer
+	name string
+
+	lock        sync.Mutex
+	discoveries map[string]mklv1alpha1.Discovery
+	nodes       map[string]mklv1alpha1.Node
+
+	informersLock sync.Mutex
+	// informers tracks the informers an event handler was added to,
+	// keyed by cluster and GVK
+	informers map[string]bool
+
+	// trigger is notified whenever the discovery should run again.
+	trigger chan struct{}
+	// changes is notified whenever the discovered nodes change.
+	changes chan struct{}
+}
+
+var _ multicluster.Aware = &Discoverer{}
+
+// New creates a new Discoverer.
+// The name distinguishes multiple Discoverers sharing the multiplexer
+// and may be empty if there is only one.
+func New(mp *multiplexer.Multiplexer, name string) *Discoverer {
+	logger := mctrl.Log.WithName("discovery")
+	if name != "" {
+		logger = logger.WithValues("diagram", name)
+	}
+
+	return &Discoverer{
+		Logger:    logger,
+		mp:        mp,
+		name:      "discovery/" + name,
+		nodes:     map[string]mklv1alpha1.Node{},
+		informers: map[string]bool{},
+		trigger:   make(chan struct{}, 1),
+		changes:   make(chan struct{}, 1),
+	}
+}
+
+// Start implements Runnable. It registers the Discoverer with the
+// multiplexer and runs the discovery whenever clusters, the
+// configuration or the watched resources change.
+func (d *Discoverer) Start(ctx context.Context) error {
+	if err := d.mp.AddAware(ctx, d.name, d); err != nil {
+		return fmt.Errorf("failed to register discovery with multiplexer: %w", err)
+	}
+	defer d.mp.DeleteAware(d.name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-d.trigger:
+		}
+
+		if err := d.discover(ctx); err != nil {
+			d.Logger.Error(err, "discovery failed")
+		}
+	}
+}
+
+// Engage implements multicluster.Aware.
+func (d *Discoverer) Engage(_ context.Context, _ multicluster.ClusterName, _ cluster.Cluster) error {
+	d.notifyTrigger()
+	return nil
+}
+
+// UpdateConfig updates the discoveries to run.
+func (d *Discoverer) UpdateConfig(discoveries map[string]mklv1alpha1.Discovery) {
+	d.lock.Lock()
+	d.discoveries = discoveries
+	d.lock.Unlock()
+
+	d.notifyTrigger()
+}
+
+// Nodes returns the discovered nodes keyed by their generated node IDs.
+func (d *Discoverer) Nodes() map[string]mklv1alpha1.Node {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return maps.Clone(d.nodes)
+}
+
+// Changes returns a channel that is notified whenever the discovered
+// nodes change.
+func (d *Discoverer) Changes() <-chan struct{} {
+	return d.changes
+}
+
+func (d *Discoverer) notifyTrigger() {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+		// a discovery is already pending
+	}
+}
+
+func (d *Discoverer) notifyChange() {
+	select {
+	case d.changes <- struct{}{}:
+	default:
+		// a notification is already pending
+	}
+}
+
+func (d *Discoverer) discover(ctx context.Context) error {
+	d.lock.Lock()
+	discoveries := d.discoveries
+	d.lock.Unlock()
+
+	nodes := map[string]mklv1alpha1.Node{}
+
+	var errs error
+
+	// The walk lists the caches, which may block until they synced,
+	// so it runs outside of the registry lock.
+	type engaged struct {
+		name    multicluster.ClusterName
+		cluster cluster.Cluster
+	}
+
+	var clusters []engaged
+
+	if err := d.mp.Registry.ForEach(func(clusterName multicluster.ClusterName, cl cluster.Cluster) error {
+		clusters = append(clusters, engaged{name: clusterName, cluster: cl})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, engaged := range clusters {
+		clusterName, cl := engaged.name, engaged.cluster
+
+		for _, discoveryName := range slices.Sorted(maps.Keys(discoveries)) {
+			discovery := discoveries[discoveryName]
+			if !discovery.Root.MatchesCluster(string(clusterName)) {
+				continue
+			}
+
+			w := &walker{
+				discoveryName: discoveryName,
+				discovery:     discovery,
+				clusterName:   string(clusterName),
+				reader:        cl.GetCache(),
+				watch: func(ctx context.Context, gvk schema.GroupVersionKind) error {
+					return d.watch(ctx, string(clusterName), cl, gvk)
+				},
+				lists: map[schema.GroupVersionKind][]unstructured.Unstructured{},
+			}
+
+			found, err := w.walk(ctx)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("discovery %s in cluster %s: %w", discoveryName, clusterName, err))
+			}
+
+			maps.Copy(nodes, found)
+		}
+	}
+
+	d.lock.Lock()
+	changed := !equality.Semantic.DeepEqual(d.nodes, nodes)
+	d.nodes = nodes
+	d.lock.Unlock()
+
+	if changed {
+		d.Logger.V(2).Info("discovered nodes changed", "nodes", len(nodes))
+		d.notifyChange()
+	}
+
+	return errs
+}
+
+// watch adds an event handler to the informer of the GVK in the cluster
+// to trigger the discovery when resources are created, deleted or
+// change their ownership.
+func (d *Discoverer) watch(ctx context.Context, clusterName string, cl cluster.Cluster, gvk schema.GroupVersionKind) error {
+	key := clusterName + "/" + gvk.String()
+
+	d.informersLock.Lock()
+	defer d.informersLock.Unlock()
+
+	if d.informers[key] {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+
+	informer, err := cl.GetCache().GetInformer(ctx, obj)
+	if err != nil {
+		return fmt.Errorf("failed to get informer for %s: %w", gvk, err)
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(any) { d.notifyTrigger() },
+		UpdateFunc: func(oldObj, newObj any) {
+			oldU, ok1 := oldObj.(*unstructured.Unstructured)
+			newU, ok2 := newObj.(*unstructured.Unstructured)
+			// status updates do not change the graph
+			if ok1 && ok2 &&
+				equality.Semantic.DeepEqual(oldU.GetOwnerReferences(), newU.GetOwnerReferences()) &&
+				equality.Semantic.DeepEqual(oldU.GetLabels(), newU.GetLabels()) {
+				return
+			}
+			d.notifyTrigger()
+		},
+		DeleteFunc: func(any) { d.notifyTrigger() },
+	}); err != nil {
+		return fmt.Errorf("failed to add event handler for %s: %w", gvk, err)
+	}
+
+	d.informers[key] = true
+
+	return nil
+}
+
+// walker walks the ownership graph of a single discovery in a single
+// cluster.
+type walker struct {
+	discoveryName string
+	discovery     mklv1alpha1.Discovery
+	clusterName   string
+	reader        client.Reader
+	// watch is called for each listed GVK to be notified about
+	// changes, it is optional.
+	watch func(ctx context.Context, gvk schema.GroupVersionKind) error
+
+	// lists caches the listed resources by GVK
+	lists map[schema.GroupVersionKind][]unstructured.Unstructured
+}
+
+func (w *walker) walk(ctx context.Context) (map[string]mklv1alpha1.Node, error) {
+	roots, err := w.roots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[string]mklv1alpha1.Node{}
+	// guard against ownership cycles
+	seen := map[types.UID]bool{}
+
+	frontier := map[types.UID]unstructured.Unstructured{}
+	for _, root := range roots {
+		seen[root.GetUID()] = true
+		frontier[root.GetUID()] = root
+		nodes[w.nodeID(root)] = mklv1alpha1.Node{Selector: w.selector(root)}
+	}
+
+	depth := w.discovery.Depth
+	if depth == 0 {
+		depth = defaultDepth
+	}
+
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		next := map[types.UID]unstructured.Unstructured{}
+
+		for _, gvk := range w.discovery.Kinds {
+			candidates, err := w.list(ctx, gvk, "")
+			if err != nil {
+				return nodes, err
+			}
+
+			for _, candidate := range candidates {
+				if seen[candidate.GetUID()] {
+					continue
+				}
+
+				owner, ok := findOwner(frontier, candidate)
+				if !ok {
+					continue
+				}
+
+				seen[candidate.GetUID()] = true
+				next[candidate.GetUID()] = candidate
+
+				selector := w.selector(candidate)
+				selector.Owner = mklv1alpha1.OwnerReference{
+					GVK:       owner.GroupVersionKind(),
+					Name:      owner.GetName(),
+					AllOwners: true,
+				}
+				nodes[w.nodeID(candidate)] = mklv1alpha1.Node{Selector: selector}
+			}
+		}
+
+		frontier = next
+	}
+
+	return nodes, nil
+}
+
+// roots returns the root resources selected by the discovery.
+func (w *walker) roots(ctx context.Context) ([]unstructured.Unstructured, error) {
+	root := w.discovery.Root
+
+	resources, err := w.list(ctx, root.GVK, root.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&root.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+
+	var ret []unstructured.Unstructured
+
+	for _, resource := range resources {
+		if root.Name != "" && resource.GetName() != root.Name {
+			continue
+		}
+
+		if !selector.Matches(labels.Set(resource.GetLabels())) {
+			continue
+		}
+
+		ret = append(ret, resource)
+	}
+
+	return ret, nil
+}
+
+func (w *walker) list(ctx context.Context, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+	if namespace == "" {
+		if list, ok := w.lists[gvk]; ok {
+			return list, nil
+		}
+	}
+
+	if w.watch != nil {
+		if err := w.watch(ctx, gvk); err != nil {
+			return nil, err
+		}
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	if err := w.reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvk, err)
+	}
+
+	for i := range list.Items {
+		// items of unstructured lists do not necessarily carry their GVK
+		list.Items[i].SetGroupVersionKind(gvk)
+	}
+
+	if namespace == "" {
+		w.lists[gvk] = list.Items
+	}
+
+	return list.Items, nil
+}
+
+// selector returns the node selector selecting the resource.
+func (w *walker) selector(resource unstructured.Unstructured) mklv1alpha1.NodeSelector {
+	return mklv1alpha1.NodeSelector{
+		ClusterName: w.clusterName,
+		GVK:         resource.GroupVersionKind(),
+		Namespace:   resource.GetNamespace(),
+		Name:        resource.GetName(),
+	}
+}
+
+var invalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// nodeID returns a mermaid node ID for the resource that is unique
+// across discoveries and clusters.
+// Replacing the characters that are invalid in IDs can map different
+// resources to the same ID, e.g. frontend-abc and frontend_abc, so a
+// hash of the original key is appended.
+func (w *walker) nodeID(resource unstructured.Unstructured) string {
+	key := []string{
+		w.discoveryName,
+		w.clusterName,
+		resource.GetKind(),
+		resource.GetNamespace(),
+		resource.GetName(),
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.Join(key, "\x00")))
+
+	return invalidIDChars.ReplaceAllString(strings.Join(key, "_"), "_") + "_" + hex.EncodeToString(h.Sum(nil))
+}
+
+// findOwner returns the owner of the resource from the candidates.
+// Owners must be in the same namespace as the resource or cluster
+// scoped.
+func findOwner(candidates map[types.UID]unstructured.Unstructured, resource unstructured.Unstructured) (unstructured.Unstructured, bool) {
+	for _, ref := range resource.GetOwnerReferences() {
+		owner, ok := candidates[ref.UID]
+		if !ok {
+			continue
+		}
+
+		if owner.GetNamespace() == "" || owner.GetNamespace() == resource.GetNamespace() {
+			return owner, true
+		}
+	}
+
+	return unstructured.Unstructured{}, false
+}
@@ -0,0 +1,129 @@
+package discovery
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	object := func(apiVersion, kind, namespace, name string, owners ...*unstructured.Unstructured) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace(namespace)
+		u.SetUID(types.UID(kind + "/" + namespace + "/" + name))
+
+		refs := make([]metav1.OwnerReference, len(owners))
+		for i, owner := range owners {
+			refs[i] = metav1.OwnerReference{
+				APIVersion: owner.GetAPIVersion(),
+				Kind:       owner.GetKind(),
+				Name:       owner.GetName(),
+				UID:        owner.GetUID(),
+				Controller: ptr.To(true),
+			}
+		}
+		u.SetOwnerReferences(refs)
+
+		return u
+	}
+
+	deployment := object("apps/v1", "Deployment", "default", "frontend")
+	replicaSet := object("apps/v1", "ReplicaSet", "default", "frontend-abc", deployment)
+	pod := object("v1", "Pod", "default", "frontend-abc-xyz", replicaSet)
+	otherPod := object("v1", "Pod", "default", "other")
+	otherDeployment := object("apps/v1", "Deployment", "other", "frontend")
+
+	reader := fake.NewClientBuilder().WithObjects(deployment, replicaSet, pod, otherPod, otherDeployment).Build()
+
+	deploymentGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	replicaSetGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+	w := &walker{
+		discoveryName: "app",
+		discovery: mklv1alpha1.Discovery{
+			Root: mklv1alpha1.NodeSelector{
+				ClusterName: "cluster",
+				GVK:         deploymentGVK,
+				Namespace:   "default",
+				Name:        "frontend",
+			},
+			Kinds: []schema.GroupVersionKind{replicaSetGVK, podGVK},
+		},
+		clusterName: "cluster",
+		reader:      reader,
+		lists:       map[schema.GroupVersionKind][]unstructured.Unstructured{},
+	}
+
+	nodes, err := w.walk(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, map[string]mklv1alpha1.Node{
+		"app_cluster_Deployment_default_frontend_c44411ecaddab5e8": {
+			Selector: mklv1alpha1.NodeSelector{
+				ClusterName: "cluster",
+				GVK:         deploymentGVK,
+				Namespace:   "default",
+				Name:        "frontend",
+			},
+		},
+		"app_cluster_ReplicaSet_default_frontend_abc_bd9153a350585fb4": {
+			Selector: mklv1alpha1.NodeSelector{
+				ClusterName: "cluster",
+				GVK:         replicaSetGVK,
+				Namespace:   "default",
+				Name:        "frontend-abc",
+				Owner:       mklv1alpha1.OwnerReference{GVK: deploymentGVK, Name: "frontend", AllOwners: true},
+			},
+		},
+		"app_cluster_Pod_default_frontend_abc_xyz_6263dc9fb7ba0f4b": {
+			Selector: mklv1alpha1.NodeSelector{
+				ClusterName: "cluster",
+				GVK:         podGVK,
+				Namespace:   "default",
+				Name:        "frontend-abc-xyz",
+				Owner:       mklv1alpha1.OwnerReference{GVK: replicaSetGVK, Name: "frontend-abc", AllOwners: true},
+			},
+		},
+	}, nodes)
+
+	w.discovery.Depth = 1
+	w.lists = map[schema.GroupVersionKind][]unstructured.Unstructured{}
+
+	nodes, err = w.walk(t.Context())
+	require.NoError(t, err)
+	require.Len(t, nodes, 2, "pods are beyond the depth")
+}
+
+func TestNodeIDUnique(t *testing.T) {
+	t.Parallel()
+
+	w := &walker{discoveryName: "app", clusterName: "cluster"}
+
+	object := func(name string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetKind("Pod")
+		u.SetNamespace("default")
+		u.SetName(name)
+
+		return u
+	}
+
+	seen := map[string]string{}
+	for _, name := range []string{"frontend-abc", "frontend_abc", "frontend.abc", "a.b", "a-b"} {
+		id := w.nodeID(object(name))
+		require.NotContains(t, seen, id, "%s and %s map to the same ID", name, seen[id])
+		seen[id] = name
+	}
+}
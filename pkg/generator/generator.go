@@ -0,0 +1,136 @@
+// Package generator generates mermaid flowcharts from
+// mermaid-kube-live configurations.
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+)
+
+// Generate generates a flowchart from the configuration.
+//
+// Nodes are grouped into subgraphs by cluster and namespace. Links are
+// drawn for the edges of the configuration and from owners to owned
+// resources if both are nodes in the configuration.
+func Generate(config *mklv1alpha1.Config) []byte {
+	g := &generator{}
+	g.line(0, "flowchart LR")
+
+	clusters := map[string]map[string][]string{}
+	for name, node := range config.Nodes {
+		cluster := strings.Join(node.Selector.ClusterPatterns(), ", ")
+		if clusters[cluster] == nil {
+			clusters[cluster] = map[string][]string{}
+		}
+		clusters[cluster][node.Selector.Namespace] = append(clusters[cluster][node.Selector.Namespace], name)
+	}
+
+	for i, cluster := range slices.Sorted(maps.Keys(clusters)) {
+		indent := 1
+		if cluster != "" {
+			g.line(indent, "subgraph mkl_cluster%d[%s]", i, text(cluster))
+			indent++
+		}
+
+		namespaces := clusters[cluster]
+		for j, namespace := range slices.Sorted(maps.Keys(namespaces)) {
+			nsIndent := indent
+			if namespace != "" {
+				g.line(nsIndent, "subgraph mkl_cluster%d_namespace%d[%s]", i, j, text(namespace))
+				nsIndent++
+			}
+
+			for _, name := range slices.Sorted(slices.Values(namespaces[namespace])) {
+				g.line(nsIndent, "%s[%s]", name, text(nodeText(name, config.Nodes[name])))
+			}
+
+			if namespace != "" {
+				g.line(indent, "end")
+			}
+		}
+
+		if cluster != "" {
+			g.line(1, "end")
+		}
+	}
+
+	for _, link := range links(config) {
+		g.line(1, "%s --> %s", link[0], link[1])
+	}
+
+	return []byte(g.String())
+}
+
+type generator struct {
+	strings.Builder
+}
+
+func (g *generator) line(indent int, format string, args ...any) {
+	g.WriteString(strings.Repeat("  ", indent))
+	fmt.Fprintf(g, format, args...)
+	g.WriteByte('\n')
+}
+
+// links returns the links between nodes as pairs of node names.
+func links(config *mklv1alpha1.Config) [][2]string {
+	var ret [][2]string
+
+	for _, name := range slices.Sorted(maps.Keys(config.Edges)) {
+		edge := config.Edges[name]
+		ret = append(ret, [2]string{edge.From, edge.To})
+	}
+
+	names := slices.Sorted(maps.Keys(config.Nodes))
+
+	for _, owned := range names {
+		ownedSelector := config.Nodes[owned].Selector
+		if ownedSelector.Owner.Name == "" {
+			continue
+		}
+
+		for _, owner := range names {
+			if owner == owned || !isOwner(config.Nodes[owner].Selector, ownedSelector) {
+				continue
+			}
+
+			link := [2]string{owner, owned}
+			if !slices.Contains(ret, link) {
+				ret = append(ret, link)
+			}
+		}
+	}
+
+	return ret
+}
+
+// isOwner returns true if the owner selector selects the owner
+// referenced by the owned selector.
+// Owners must be in the same clusters and either in the same namespace
+// or cluster scoped.
+func isOwner(owner, owned mklv1alpha1.NodeSelector) bool {
+	return owner.GVK == owned.Owner.GVK &&
+		owner.Name == owned.Owner.Name &&
+		slices.Equal(owner.ClusterPatterns(), owned.ClusterPatterns()) &&
+		(owner.Namespace == "" || owner.Namespace == owned.Namespace)
+}
+
+// nodeText returns the text displayed for a node, e.g. "Secret name".
+func nodeText(name string, node mklv1alpha1.Node) string {
+	switch {
+	case node.Selector.GVK.Kind != "" && node.Selector.Name != "":
+		return node.Selector.GVK.Kind + " " + node.Selector.Name
+	case node.Selector.GVK.Kind != "":
+		return node.Selector.GVK.Kind + " " + name
+	default:
+		return name
+	}
+}
+
+// text quotes the text for use as node or subgraph text.
+func text(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	config, err := mklv1alpha1.Parse([]byte(`
+nodes:
+  deployment:
+    selector:
+      clusterName: cluster1
+      namespace: default
+      gvk:
+        group: apps
+        version: v1
+        kind: Deployment
+      name: app
+  pods:
+    selector:
+      clusterName: cluster1
+      namespace: default
+      gvk:
+        version: v1
+        kind: Pod
+      owner:
+        gvk:
+          group: apps
+          version: v1
+          kind: Deployment
+        name: app
+        depth: 2
+  namespace:
+    selector:
+      clusterName: cluster1
+      gvk:
+        version: v1
+        kind: Namespace
+      name: default
+  secret:
+    selector:
+      clusterNames: [cluster2, cluster3]
+      namespace: default
+      gvk:
+        version: v1
+        kind: Secret
+      name: '"quoted"'
+  unbound:
+    label: '"unbound"'
+edges:
+  copy:
+    from: namespace
+    to: secret
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, `flowchart LR
+  unbound["unbound"]
+  subgraph mkl_cluster1["cluster1"]
+    namespace["Namespace default"]
+    subgraph mkl_cluster1_namespace1["default"]
+      deployment["Deployment app"]
+      pods["Pod pods"]
+    end
+  end
+  subgraph mkl_cluster2["cluster2, cluster3"]
+    subgraph mkl_cluster2_namespace0["default"]
+      secret["Secret #quot;quoted#quot;"]
+    end
+  end
+  namespace --> secret
+  deployment --> pods
+`, string(Generate(config)))
+}
@@ -0,0 +1,371 @@
+package manifests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// objectKey identifies a resource in a cluster.
+type objectKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+func keyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{
+		gvk:       obj.GroupVersionKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// Cluster is a cluster.Cluster serving resources from manifests.
+// Reads are served from memory and changes to the resources are
+// delivered to the informers of the cache.
+type Cluster struct {
+	scheme *runtime.Scheme
+	client client.WithWatch
+
+	// updateLock serializes updates so events are delivered in order
+	updateLock sync.Mutex
+	lock       sync.Mutex
+	objects    map[objectKey]*unstructured.Unstructured
+	informers  map[schema.GroupVersionKind]*informer
+}
+
+var _ cluster.Cluster = &Cluster{}
+
+// NewCluster creates a new Cluster with the resources.
+func NewCluster(ctx context.Context, resources []*unstructured.Unstructured) (*Cluster, error) {
+	c := &Cluster{
+		scheme:    clientgoscheme.Scheme,
+		client:    fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(),
+		objects:   make(map[objectKey]*unstructured.Unstructured),
+		informers: make(map[schema.GroupVersionKind]*informer),
+	}
+
+	if err := c.Update(ctx, resources); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Update replaces the resources of the cluster and notifies the
+// informers about the added, updated and deleted resources.
+func (c *Cluster) Update(ctx context.Context, resources []*unstructured.Unstructured) error {
+	c.updateLock.Lock()
+	defer c.updateLock.Unlock()
+
+	// Events are delivered after releasing the lock as the informers
+	// list the resources when handlers are added.
+	var notify []func()
+	defer func() {
+		for _, fn := range notify {
+			fn()
+		}
+	}()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	desired := make(map[objectKey]*unstructured.Unstructured, len(resources))
+	for _, obj := range resources {
+		key := keyOf(obj)
+		if _, ok := desired[key]; ok {
+			return fmt.Errorf("resource %s %s/%s is defined multiple times", key.gvk.Kind, key.namespace, key.name)
+		}
+
+		desired[key] = obj
+	}
+
+	var errs []error
+
+	for key := range c.objects {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+
+		fn, err := c.remove(ctx, key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		notify = append(notify, fn)
+	}
+
+	for _, obj := range desired {
+		fn, err := c.apply(ctx, obj)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if fn != nil {
+			notify = append(notify, fn)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply adds or updates a single resource and notifies the informers.
+func (c *Cluster) Apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	c.updateLock.Lock()
+	defer c.updateLock.Unlock()
+
+	c.lock.Lock()
+	fn, err := c.apply(ctx, obj)
+	c.lock.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+
+	return err
+}
+
+// Delete deletes a single resource and notifies the informers.
+// Deleting a resource that does not exist is not an error.
+func (c *Cluster) Delete(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) error {
+	c.updateLock.Lock()
+	defer c.updateLock.Unlock()
+
+	key := objectKey{gvk: gvk, namespace: namespace, name: name}
+
+	c.lock.Lock()
+	if _, ok := c.objects[key]; !ok {
+		c.lock.Unlock()
+		return nil
+	}
+
+	fn, err := c.remove(ctx, key)
+	c.lock.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+
+	return err
+}
+
+// apply stores the resource and returns a function notifying the
+// informers, which is nil if the resource did not change.
+// The caller must hold the lock.
+func (c *Cluster) apply(ctx context.Context, obj *unstructured.Unstructured) (func(), error) {
+	key := keyOf(obj)
+
+	old, exists := c.objects[key]
+	if exists && equality.Semantic.DeepEqual(old.Object, obj.Object) {
+		return nil, nil
+	}
+
+	if err := c.store(ctx, obj, exists); err != nil {
+		return nil, fmt.Errorf("failed to store %s %s/%s: %w", key.gvk.Kind, key.namespace, key.name, err)
+	}
+
+	c.objects[key] = obj.DeepCopy()
+
+	informer := c.informerFor(key.gvk)
+	if exists {
+		return func() { informer.update(old.DeepCopy(), obj.DeepCopy()) }, nil
+	}
+
+	return func() { informer.add(obj.DeepCopy()) }, nil
+}
+
+// remove deletes the resource and returns a function notifying the
+// informers.
+// The caller must hold the lock.
+func (c *Cluster) remove(ctx context.Context, key objectKey) (func(), error) {
+	obj := c.objects[key]
+
+	if err := c.client.Delete(ctx, obj.DeepCopy()); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("failed to delete %s %s/%s: %w", key.gvk.Kind, key.namespace, key.name, err)
+	}
+
+	delete(c.objects, key)
+
+	informer := c.informerFor(key.gvk)
+
+	return func() { informer.delete(obj.DeepCopy()) }, nil
+}
+
+// store writes the resource to the client.
+// Changed resources are recreated as the resource versions in the
+// manifests are not meaningful for the client.
+func (c *Cluster) store(ctx context.Context, obj *unstructured.Unstructured, exists bool) error {
+	if exists {
+		if err := c.client.Delete(ctx, obj.DeepCopy()); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	stored := obj.DeepCopy()
+	stored.SetResourceVersion("")
+
+	return c.client.Create(ctx, stored)
+}
+
+// informerFor returns the informer for the kind, creating it if
+// necessary.
+// The caller must hold the lock.
+func (c *Cluster) informerFor(gvk schema.GroupVersionKind) *informer {
+	if i, ok := c.informers[gvk]; ok {
+		return i
+	}
+
+	i := &informer{
+		list: func() []*unstructured.Unstructured {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+
+			return c.list(gvk)
+		},
+	}
+	c.informers[gvk] = i
+
+	return i
+}
+
+// list returns copies of the resources of the kind.
+// The caller must hold the lock.
+func (c *Cluster) list(gvk schema.GroupVersionKind) []*unstructured.Unstructured {
+	var ret []*unstructured.Unstructured
+
+	for key, obj := range c.objects {
+		if key.gvk == gvk {
+			ret = append(ret, obj.DeepCopy())
+		}
+	}
+
+	return ret
+}
+
+// GetHTTPClient implements cluster.Cluster.
+func (c *Cluster) GetHTTPClient() *http.Client {
+	return http.DefaultClient
+}
+
+// GetConfig implements cluster.Cluster.
+func (c *Cluster) GetConfig() *rest.Config {
+	return &rest.Config{}
+}
+
+// GetCache implements cluster.Cluster.
+func (c *Cluster) GetCache() cache.Cache {
+	return &manifestCache{Reader: c.client, cluster: c}
+}
+
+// GetScheme implements cluster.Cluster.
+func (c *Cluster) GetScheme() *runtime.Scheme {
+	return c.scheme
+}
+
+// GetClient implements cluster.Cluster.
+// Writes through the client only change the resources in memory and
+// are not delivered to informers.
+func (c *Cluster) GetClient() client.Client {
+	return c.client
+}
+
+// GetFieldIndexer implements cluster.Cluster.
+func (c *Cluster) GetFieldIndexer() client.FieldIndexer {
+	return c.GetCache()
+}
+
+// GetRESTMapper implements cluster.Cluster.
+func (c *Cluster) GetRESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GetAPIReader implements cluster.Cluster.
+func (c *Cluster) GetAPIReader() client.Reader {
+	return c.client
+}
+
+// GetEventRecorderFor implements cluster.Cluster.
+// Events are discarded.
+func (c *Cluster) GetEventRecorderFor(_ string) record.EventRecorder {
+	return &record.FakeRecorder{}
+}
+
+// GetEventRecorder implements cluster.Cluster.
+// Events are discarded.
+func (c *Cluster) GetEventRecorder(_ string) events.EventRecorder {
+	return &events.FakeRecorder{}
+}
+
+// Start implements cluster.Cluster.
+func (c *Cluster) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// manifestCache is a cache.Cache serving the resources of a Cluster.
+type manifestCache struct {
+	client.Reader
+	cluster *Cluster
+}
+
+var _ cache.Cache = &manifestCache{}
+
+// GetInformer implements cache.Informers.
+func (m *manifestCache) GetInformer(ctx context.Context, obj client.Object, opts ...cache.InformerGetOption) (cache.Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, m.cluster.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.GetInformerForKind(ctx, gvk, opts...)
+}
+
+// GetInformerForKind implements cache.Informers.
+func (m *manifestCache) GetInformerForKind(_ context.Context, gvk schema.GroupVersionKind, _ ...cache.InformerGetOption) (cache.Informer, error) {
+	m.cluster.lock.Lock()
+	defer m.cluster.lock.Unlock()
+
+	return m.cluster.informerFor(gvk), nil
+}
+
+// RemoveInformer implements cache.Informers.
+// Informers are kept as they hold no resources.
+func (m *manifestCache) RemoveInformer(_ context.Context, _ client.Object) error {
+	return nil
+}
+
+// Start implements cache.Informers.
+func (m *manifestCache) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// WaitForCacheSync implements cache.Informers.
+func (m *manifestCache) WaitForCacheSync(_ context.Context) bool {
+	return true
+}
+
+// IndexField implements client.FieldIndexer.
+func (m *manifestCache) IndexField(_ context.Context, _ client.Object, _ string, _ client.IndexerFunc) error {
+	return errors.New("field indexes are not supported for manifests")
+}
@@ -0,0 +1,85 @@
+package manifests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newResource(kind, name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind(kind)
+	u.SetNamespace("default")
+	u.SetName(name)
+	u.SetLabels(labels)
+
+	return u
+}
+
+type recorder struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (r *recorder) handler() toolscache.ResourceEventHandler {
+	record := func(event string, obj any) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		r.events = append(r.events, event+" "+obj.(client.Object).GetName())
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { record("add", obj) },
+		UpdateFunc: func(_, obj any) { record("update", obj) },
+		DeleteFunc: func(obj any) { record("delete", obj) },
+	}
+}
+
+func TestCluster(t *testing.T) {
+	t.Parallel()
+
+	cl, err := NewCluster(t.Context(), []*unstructured.Unstructured{
+		newResource("Widget", "a", map[string]string{"app": "a"}),
+		newResource("Widget", "b", nil),
+		newResource("Gadget", "c", nil),
+	})
+	require.NoError(t, err)
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion("example.com/v1")
+	list.SetKind("WidgetList")
+	require.NoError(t, cl.GetCache().List(t.Context(), list, client.MatchingLabels{"app": "a"}))
+	require.Len(t, list.Items, 1)
+
+	informer, err := cl.GetCache().GetInformer(t.Context(), newResource("Widget", "", nil))
+	require.NoError(t, err)
+	require.True(t, informer.HasSynced())
+
+	rec := &recorder{}
+	_, err = informer.AddEventHandler(rec.handler())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"add a", "add b"}, rec.events)
+
+	rec.events = nil
+	require.NoError(t, cl.Update(t.Context(), []*unstructured.Unstructured{
+		newResource("Widget", "a", map[string]string{"app": "changed"}),
+		newResource("Widget", "d", nil),
+		newResource("Gadget", "c", nil),
+	}))
+	require.ElementsMatch(t, []string{"update a", "add d", "delete b"}, rec.events)
+
+	got := newResource("Widget", "", nil)
+	require.NoError(t, cl.GetClient().Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "a"}, got))
+	require.Equal(t, "changed", got.GetLabels()["app"])
+
+	require.Error(t, cl.Update(t.Context(), []*unstructured.Unstructured{
+		newResource("Widget", "a", nil),
+		newResource("Widget", "a", nil),
+	}))
+}
@@ -0,0 +1,7 @@
+// Package manifests provides a multicluster.Provider that serves
+// resources from YAML manifests instead of live clusters.
+//
+// This allows to design diagrams and to reproduce incidents from
+// captured state, e.g. `kubectl get -o yaml` dumps, without any access
+// to the clusters.
+package manifests
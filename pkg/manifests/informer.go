@@ -0,0 +1,117 @@
+package manifests
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// informer delivers the changes to the resources of one kind to its
+// event handlers.
+// The manifests are always fully loaded, so the informer is always
+// synced.
+type informer struct {
+	lock     sync.Mutex
+	handlers []*registration
+	// list returns the current resources of the kind
+	list func() []*unstructured.Unstructured
+}
+
+var _ cache.Informer = &informer{}
+
+// registration is the registration of an event handler.
+type registration struct {
+	handler toolscache.ResourceEventHandler
+}
+
+// HasSynced implements toolscache.ResourceEventHandlerRegistration.
+func (r *registration) HasSynced() bool {
+	return true
+}
+
+// AddEventHandler implements cache.Informer.
+// The handler is notified about all existing resources before it is
+// returned.
+func (i *informer) AddEventHandler(handler toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, obj := range i.list() {
+		handler.OnAdd(obj, true)
+	}
+
+	reg := &registration{handler: handler}
+	i.handlers = append(i.handlers, reg)
+
+	return reg, nil
+}
+
+// AddEventHandlerWithResyncPeriod implements cache.Informer.
+// Resyncs are not supported and the period is ignored.
+func (i *informer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, _ time.Duration) (toolscache.ResourceEventHandlerRegistration, error) {
+	return i.AddEventHandler(handler)
+}
+
+// AddEventHandlerWithOptions implements cache.Informer.
+func (i *informer) AddEventHandlerWithOptions(handler toolscache.ResourceEventHandler, _ toolscache.HandlerOptions) (toolscache.ResourceEventHandlerRegistration, error) {
+	return i.AddEventHandler(handler)
+}
+
+// RemoveEventHandler implements cache.Informer.
+func (i *informer) RemoveEventHandler(handle toolscache.ResourceEventHandlerRegistration) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.handlers = slices.DeleteFunc(i.handlers, func(reg *registration) bool {
+		return reg == handle
+	})
+
+	return nil
+}
+
+// AddIndexers implements cache.Informer.
+func (i *informer) AddIndexers(_ toolscache.Indexers) error {
+	return errors.New("indexers are not supported for manifests")
+}
+
+// HasSynced implements cache.Informer.
+func (i *informer) HasSynced() bool {
+	return true
+}
+
+// IsStopped implements cache.Informer.
+func (i *informer) IsStopped() bool {
+	return false
+}
+
+func (i *informer) add(obj *unstructured.Unstructured) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, reg := range i.handlers {
+		reg.handler.OnAdd(obj, false)
+	}
+}
+
+func (i *informer) update(oldObj, newObj *unstructured.Unstructured) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, reg := range i.handlers {
+		reg.handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (i *informer) delete(obj *unstructured.Unstructured) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, reg := range i.handlers {
+		reg.handler.OnDelete(obj)
+	}
+}
@@ -0,0 +1,148 @@
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Parse parses the resources in YAML manifests. The manifests can
+// contain multiple documents and lists of resources as returned by
+// `kubectl get -o yaml`.
+func Parse(data []byte) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+	var resources []*unstructured.Unstructured
+
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return resources, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document: %w", err)
+		}
+
+		obj := map[string]any{}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+
+		if len(obj) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+
+		if !u.IsList() {
+			if u.GetKind() == "" || u.GetAPIVersion() == "" {
+				return nil, fmt.Errorf("resource %q has no apiVersion or kind", u.GetName())
+			}
+
+			resources = append(resources, u)
+
+			continue
+		}
+
+		list, err := u.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse list: %w", err)
+		}
+
+		for i := range list.Items {
+			resources = append(resources, &list.Items[i])
+		}
+	}
+}
+
+// isManifest returns true if the path is a YAML file.
+func isManifest(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// clusterName returns the name of the cluster for a file or directory.
+func clusterName(path string) string {
+	base := filepath.Base(path)
+	if isManifest(base) {
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	return base
+}
+
+// loadDir loads the resources of all clusters in the directory.
+// Each YAML file and each subdirectory in the directory is a cluster
+// named after the file without extension or the subdirectory.
+// Subdirectories are read recursively.
+func loadDir(dir string) (map[string][]*unstructured.Unstructured, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	clusters := map[string][]*unstructured.Unstructured{}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && !isManifest(path) {
+			continue
+		}
+
+		name := clusterName(path)
+		if _, ok := clusters[name]; ok {
+			return nil, fmt.Errorf("cluster %s is defined by multiple files or directories in %s", name, dir)
+		}
+
+		resources, err := loadPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		clusters[name] = resources
+	}
+
+	return clusters, nil
+}
+
+// loadPath loads the resources in a file or recursively in all YAML
+// files in a directory.
+func loadPath(path string) ([]*unstructured.Unstructured, error) {
+	var resources []*unstructured.Unstructured
+
+	err := filepath.WalkDir(path, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !isManifest(path) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parsed, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		resources = append(resources, parsed...)
+
+		return nil
+	})
+
+	return resources, err
+}
@@ -0,0 +1,68 @@
+package manifests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	resources, err := Parse([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+  namespace: default
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: second
+    namespace: default
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: third
+    namespace: default
+`))
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+	require.Equal(t, "ConfigMap", resources[0].GetKind())
+	require.Equal(t, "second", resources[1].GetName())
+	require.Equal(t, "apps/v1", resources[2].GetAPIVersion())
+
+	_, err = Parse([]byte("metadata:\n  name: nokind\n"))
+	require.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secret := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n  namespace: default\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster1.yaml"), secret, 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cluster2", "nested"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster2", "a.yml"), secret, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster2", "nested", "b.yaml"), secret, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+	clusters, err := loadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	require.Len(t, clusters["cluster1"], 1)
+	require.Len(t, clusters["cluster2"], 2)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster2.yaml"), secret, 0o600))
+
+	_, err = loadDir(dir)
+	require.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package manifests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	mctrl "sigs.k8s.io/multicluster-runtime"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// Options are the options for the Provider.
+type Options struct {
+	// Path is the directory containing the manifests.
+	// Each YAML file and each subdirectory in the directory is a
+	// cluster named after the file without extension or the
+	// subdirectory.
+	Path string
+}
+
+// Provider is a multicluster.Provider presenting directories of YAML
+// manifests as clusters.
+// Changes to the manifests are picked up and delivered as changes to
+// the resources.
+type Provider struct {
+	opts   Options
+	logger logr.Logger
+
+	lock     sync.Mutex
+	clusters map[multicluster.ClusterName]*engagedCluster
+}
+
+type engagedCluster struct {
+	cluster *Cluster
+	cancel  context.CancelFunc
+}
+
+var (
+	_ multicluster.Provider         = &Provider{}
+	_ multicluster.ProviderRunnable = &Provider{}
+)
+
+// New creates a new Provider.
+func New(opts Options) (*Provider, error) {
+	if opts.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	return &Provider{
+		opts:     opts,
+		logger:   mctrl.Log.WithName("manifests-provider"),
+		clusters: make(map[multicluster.ClusterName]*engagedCluster),
+	}, nil
+}
+
+// Get implements multicluster.Provider.
+func (p *Provider) Get(_ context.Context, clusterName multicluster.ClusterName) (cluster.Cluster, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	engaged, ok := p.clusters[clusterName]
+	if !ok {
+		return nil, multicluster.ErrClusterNotFound
+	}
+
+	return engaged.cluster, nil
+}
+
+// IndexField implements multicluster.Provider.
+func (p *Provider) IndexField(_ context.Context, _ client.Object, _ string, _ client.IndexerFunc) error {
+	return errors.New("field indexes are not supported for manifests")
+}
+
+// Start implements multicluster.ProviderRunnable.
+// It loads the manifests, engages the clusters and updates them when
+// the manifests change. It blocks until the context is done.
+func (p *Provider) Start(ctx context.Context, aware multicluster.Aware) error {
+	if err := p.run(ctx, aware); err != nil {
+		return fmt.Errorf("initial update failed: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close() //nolint:errcheck
+
+	if err := p.watchDirs(watcher); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("file watcher closed")
+			}
+
+			p.logger.V(2).Info("received fsnotify event", "event", event)
+
+			if err := p.run(ctx, aware); err != nil {
+				p.logger.Error(err, "failed to update clusters after file change")
+			}
+
+			// new subdirectories must be watched as well
+			if err := p.watchDirs(watcher); err != nil {
+				p.logger.Error(err, "failed to watch directories")
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("file watcher errors channel closed")
+			}
+
+			p.logger.Error(err, "file watcher error")
+		}
+	}
+}
+
+// watchDirs adds the directory and all its subdirectories to the
+// watcher.
+func (p *Provider) watchDirs(watcher *fsnotify.Watcher) error {
+	return filepath.WalkDir(p.opts.Path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch directory %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
+
+// run loads the manifests and adds, updates and removes clusters
+// accordingly.
+// If the manifests cannot be loaded the clusters are left unchanged.
+func (p *Provider) run(ctx context.Context, aware multicluster.Aware) error {
+	loaded, err := loadDir(p.opts.Path)
+	if err != nil {
+		return err
+	}
+
+	// run is only called sequentially, the lock only guards the map
+	// against concurrent calls to Get - which may also happen while
+	// engaging clusters.
+	p.lock.Lock()
+	known := maps.Clone(p.clusters)
+	p.lock.Unlock()
+
+	var errs []error
+
+	for name, resources := range loaded {
+		clusterName := multicluster.ClusterName(name)
+
+		if engaged, ok := known[clusterName]; ok {
+			p.logger.V(2).Info("updating cluster", "cluster", clusterName, "resources", len(resources))
+
+			if err := engaged.cluster.Update(ctx, resources); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update cluster %s: %w", clusterName, err))
+			}
+
+			continue
+		}
+
+		p.logger.Info("adding cluster", "cluster", clusterName, "resources", len(resources))
+
+		cl, err := NewCluster(ctx, resources)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create cluster %s: %w", clusterName, err))
+			continue
+		}
+
+		clusterCtx, cancel := context.WithCancel(ctx)
+
+		p.lock.Lock()
+		p.clusters[clusterName] = &engagedCluster{cluster: cl, cancel: cancel}
+		p.lock.Unlock()
+
+		if err := aware.Engage(clusterCtx, clusterName, cl); err != nil {
+			cancel()
+			errs = append(errs, fmt.Errorf("failed to engage cluster %s: %w", clusterName, err))
+
+			p.lock.Lock()
+			delete(p.clusters, clusterName)
+			p.lock.Unlock()
+		}
+	}
+
+	for clusterName, engaged := range known {
+		if _, ok := loaded[string(clusterName)]; ok {
+			continue
+		}
+
+		p.logger.Info("removing cluster", "cluster", clusterName)
+
+		// deleting all resources first so nodes do not keep showing
+		// the last state of the cluster
+		if err := engaged.cluster.Update(ctx, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clear cluster %s: %w", clusterName, err))
+		}
+
+		engaged.cancel()
+
+		p.lock.Lock()
+		delete(p.clusters, clusterName)
+		p.lock.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package manifests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+type engager struct {
+	lock     sync.Mutex
+	clusters map[multicluster.ClusterName]context.Context
+}
+
+func (e *engager) Engage(ctx context.Context, name multicluster.ClusterName, _ cluster.Cluster) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.clusters[name] = ctx
+
+	return nil
+}
+
+func (e *engager) engaged(name multicluster.ClusterName) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	ctx, ok := e.clusters[name]
+
+	return ok && ctx.Err() == nil
+}
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secret := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n  namespace: default\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster1.yaml"), secret, 0o600))
+
+	provider, err := New(Options{Path: dir})
+	require.NoError(t, err)
+
+	aware := &engager{clusters: map[multicluster.ClusterName]context.Context{}}
+
+	go func() {
+		_ = provider.Start(t.Context(), aware)
+	}()
+
+	require.Eventually(t, func() bool { return aware.engaged("cluster1") }, 5*time.Second, 10*time.Millisecond)
+
+	cl, err := provider.Get(t.Context(), "cluster1")
+	require.NoError(t, err)
+	require.NotNil(t, cl)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster2.yaml"), secret, 0o600))
+	require.Eventually(t, func() bool { return aware.engaged("cluster2") }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "cluster1.yaml")))
+	require.Eventually(t, func() bool { return !aware.engaged("cluster1") }, 5*time.Second, 10*time.Millisecond)
+
+	_, err = provider.Get(t.Context(), "cluster1")
+	require.ErrorIs(t, err, multicluster.ErrClusterNotFound)
+}
@@ -0,0 +1,3 @@
+// Package mermaid parses the structure of mermaid flowcharts - nodes,
+// subgraphs and links - that mermaid-kube-live needs to decorate them.
+package mermaid
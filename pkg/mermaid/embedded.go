@@ -0,0 +1,127 @@
+package mermaid
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Embedded extracts YAML embedded under the given key from the
+// flowchart. The YAML can be embedded as top level key in the
+// frontmatter or as comment block, e.g.:
+//
+//	%% mkl:
+//	%%   nodes:
+//	%%     ...
+//
+// Embedded returns each embedded block as YAML document with the key as
+// root and the flowchart without the blocks, so mermaid does not
+// trip over unknown frontmatter keys.
+func Embedded(flowchart []byte, key string) ([][]byte, []byte) {
+	var (
+		docs     [][]byte
+		stripped []byte
+	)
+
+	rest := flowchart
+
+	if fm, end, ok := frontmatter(flowchart); ok {
+		doc, remaining := extractKey(fm, key)
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+
+		if len(bytes.TrimSpace(remaining)) > 0 {
+			stripped = append(stripped, "---\n"...)
+			stripped = append(stripped, remaining...)
+			stripped = append(stripped, flowchart[4+len(fm):end]...)
+		}
+
+		rest = flowchart[end:]
+	}
+
+	prefix := "%% " + key + ":"
+	lines := splitLines(rest)
+
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != prefix {
+			stripped = append(stripped, lines[i]...)
+			continue
+		}
+
+		doc := []byte(key + ":\n")
+
+		// The block continues as long as the comments are indented.
+		for i++; i < len(lines); i++ {
+			line, ok := strings.CutPrefix(strings.TrimLeft(lines[i], " \t"), "%%")
+			if !ok {
+				break
+			}
+
+			line = strings.TrimPrefix(line, " ")
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				break
+			}
+
+			doc = append(doc, line...)
+		}
+		i--
+
+		docs = append(docs, doc)
+	}
+
+	return docs, stripped
+}
+
+// frontmatter returns the content of the frontmatter and the offset of
+// the first byte after it.
+func frontmatter(flowchart []byte) ([]byte, int, bool) {
+	if !bytes.HasPrefix(flowchart, []byte("---\n")) {
+		return nil, 0, false
+	}
+
+	i := bytes.Index(flowchart[4:], []byte("\n---"))
+	if i < 0 {
+		return nil, 0, false
+	}
+
+	end := 4 + i + 4
+	if nl := bytes.IndexByte(flowchart[end:], '\n'); nl >= 0 {
+		end += nl + 1
+	} else {
+		end = len(flowchart)
+	}
+
+	return flowchart[4 : 4+i+1], end, true
+}
+
+// extractKey extracts the top level key with its indented value from
+// the YAML and returns it and the remaining YAML.
+func extractKey(yaml []byte, key string) ([]byte, []byte) {
+	var doc, remaining []byte
+
+	lines := splitLines(yaml)
+
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], key+":") {
+			remaining = append(remaining, lines[i]...)
+			continue
+		}
+
+		doc = append(doc, lines[i]...)
+		for i++; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(lines[i], " ") && !strings.HasPrefix(lines[i], "\t") {
+				break
+			}
+
+			doc = append(doc, lines[i]...)
+		}
+		i--
+	}
+
+	return doc, remaining
+}
+
+// splitLines splits the data into lines, keeping the line endings.
+func splitLines(data []byte) []string {
+	return strings.SplitAfter(string(data), "\n")
+}
@@ -0,0 +1,53 @@
+package mermaid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedded(t *testing.T) {
+	t.Parallel()
+
+	flowchart := `---
+title: example
+mkl:
+  nodes:
+    a:
+      label: '"a"'
+config:
+  theme: dark
+---
+flowchart TD
+  a --> b
+  %% mkl:
+  %%   nodes:
+  %%     b:
+  %%       label: '"b"'
+  %% a regular comment
+  b --> c
+`
+
+	docs, stripped := Embedded([]byte(flowchart), "mkl")
+	require.Len(t, docs, 2)
+	require.Equal(t, "mkl:\n  nodes:\n    a:\n      label: '\"a\"'\n", string(docs[0]))
+	require.Equal(t, "mkl:\n  nodes:\n    b:\n      label: '\"b\"'\n", string(docs[1]))
+	require.Equal(t, `---
+title: example
+config:
+  theme: dark
+---
+flowchart TD
+  a --> b
+  %% a regular comment
+  b --> c
+`, string(stripped))
+
+	docs, stripped = Embedded([]byte("---\nmkl: {}\n---\nflowchart TD\n"), "mkl")
+	require.Equal(t, []string{"mkl: {}\n"}, []string{string(docs[0])})
+	require.Equal(t, "flowchart TD\n", string(stripped), "empty frontmatter should be removed")
+
+	docs, stripped = Embedded([]byte("flowchart TD\n  a --> b\n"), "mkl")
+	require.Empty(t, docs)
+	require.Equal(t, "flowchart TD\n  a --> b\n", string(stripped))
+}
@@ -0,0 +1,69 @@
+package mermaid
+
+// Flowchart is the structure of a mermaid flowchart.
+type Flowchart struct {
+	// Nodes are the nodes of the flowchart in the order of their first
+	// appearance.
+	Nodes []Node
+	// Subgraphs are the subgraphs of the flowchart in the order of
+	// their declaration.
+	Subgraphs []Subgraph
+	// Links are the links of the flowchart in the order in which
+	// mermaid numbers them for linkStyle.
+	Links []Link
+}
+
+// Node is a node in a flowchart.
+type Node struct {
+	// ID is the ID of the node.
+	ID string
+	// Subgraph is the ID of the innermost subgraph the node first
+	// appeared in, empty if the node is not in a subgraph.
+	Subgraph string
+}
+
+// Subgraph is a subgraph in a flowchart.
+type Subgraph struct {
+	// ID is the ID of the subgraph.
+	ID string
+	// Title is the title displayed for the subgraph.
+	Title string
+	// Parent is the ID of the subgraph this subgraph is nested in,
+	// empty if it is not nested.
+	Parent string
+}
+
+// Parse parses the flowchart.
+// The parser is lenient and skips statements it does not understand.
+func Parse(flowchart []byte) *Flowchart {
+	p := &parser{
+		src:       flowchart,
+		flowchart: &Flowchart{},
+		seen:      map[string]bool{},
+	}
+	p.parse()
+
+	return p.flowchart
+}
+
+// Node returns the node with the given ID or nil.
+func (f *Flowchart) Node(id string) *Node {
+	for i := range f.Nodes {
+		if f.Nodes[i].ID == id {
+			return &f.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// Subgraph returns the subgraph with the given ID or nil.
+func (f *Flowchart) Subgraph(id string) *Subgraph {
+	for i := range f.Subgraphs {
+		if f.Subgraphs[i].ID == id {
+			return &f.Subgraphs[i]
+		}
+	}
+
+	return nil
+}
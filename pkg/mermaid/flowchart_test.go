@@ -0,0 +1,111 @@
+package mermaid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	flowchart := Parse([]byte(`---
+title: example
+---
+flowchart TD
+  top
+  subgraph cluster1[Cluster 1]
+    direction LR
+    a1[out-first-secret] --> a2("a --> b")
+    subgraph ns1 ["namespace"]
+      a3 & a4
+    end
+  end
+  subgraph cluster 2
+    b1:::someclass
+  end
+  subgraph "quoted"
+  end
+  cluster1 --> b1 --> c
+  style a1 fill:#f9f
+  class a1,b1 someclass
+`))
+
+	require.Equal(t, []Node{
+		{ID: "top"},
+		{ID: "a1", Subgraph: "cluster1"},
+		{ID: "a2", Subgraph: "cluster1"},
+		{ID: "a3", Subgraph: "ns1"},
+		{ID: "a4", Subgraph: "ns1"},
+		{ID: "b1", Subgraph: "cluster 2"},
+		{ID: "c"},
+	}, flowchart.Nodes)
+
+	require.Equal(t, []Subgraph{
+		{ID: "cluster1", Title: "Cluster 1"},
+		{ID: "ns1", Title: "namespace", Parent: "cluster1"},
+		{ID: "cluster 2", Title: "cluster 2"},
+		{ID: "quoted", Title: "quoted"},
+	}, flowchart.Subgraphs)
+
+	require.Len(t, flowchart.Links, 3)
+	require.Equal(t, "cluster1", flowchart.Links[1].From)
+	require.Equal(t, "b1", flowchart.Links[1].To)
+
+	require.NotNil(t, flowchart.Node("a3"))
+	require.Nil(t, flowchart.Node("cluster1"))
+	require.NotNil(t, flowchart.Subgraph("ns1"))
+}
+
+func TestParseMalformed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		flowchart string
+		expected  []Subgraph
+	}{
+		"unclosed subgraph title": {
+			flowchart: "flowchart TD\n  subgraph id [\n  end\n",
+			expected:  []Subgraph{{ID: "id", Title: ""}},
+		},
+		"unclosed subgraph title at end": {
+			flowchart: "flowchart TD\n  subgraph id [",
+			expected:  []Subgraph{{ID: "id", Title: ""}},
+		},
+		"unclosed subgraph title with text": {
+			flowchart: "flowchart TD\n  subgraph id [some title\n  end\n",
+			expected:  []Subgraph{{ID: "id", Title: "some title"}},
+		},
+		"unclosed quoted subgraph title": {
+			flowchart: "flowchart TD\n  subgraph id [\"title",
+			expected:  []Subgraph{{ID: "id", Title: "title"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, Parse([]byte(tc.flowchart)).Subgraphs)
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"flowchart TD\n  subgraph id [\n",
+		"flowchart TD\n  subgraph id [",
+		"---\nmkl:\n  nodes: {}\n---\nflowchart TD\n  %% mkl:\n  %%   edges: {}\n",
+		"---\ntitle: x\n---\nflowchart TD\n  a[A] --> b(B)\n",
+		"flowchart TD\n  a -- label --> b & c\n  b -.->|\"a|b\"| c:::class\n",
+		"flowchart TD\n  a e1@==> b{{c}}; b --o d\n  subgraph s [\"t\"]\n  end\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(_ *testing.T, flowchart []byte) {
+		Parse(flowchart)
+		Links(flowchart)
+		Embedded(flowchart, "mkl")
+	})
+}
@@ -0,0 +1,65 @@
+package mermaid
+
+import "strings"
+
+// Link is a link between two nodes in a flowchart.
+type Link struct {
+	// Index is the position of the link in the flowchart as used by
+	// linkStyle.
+	Index int
+
+	// From is the ID of the node the link starts at.
+	From string
+	// To is the ID of the node the link ends at.
+	To string
+
+	// Arrow is the link without its label, e.g. "-->" or "-.->".
+	Arrow string
+	// Label is the label of the link, if any.
+	Label string
+
+	// Start and End are the byte offsets of the link including its
+	// label in the flowchart.
+	Start, End int
+}
+
+// Links returns all links of the flowchart in the order in which
+// mermaid numbers them for linkStyle.
+func Links(flowchart []byte) []Link {
+	return Parse(flowchart).Links
+}
+
+// SetLinkLabels returns a copy of the flowchart with the labels of the
+// given links replaced. The labels are keyed by the index of the links.
+// The labels are quoted and escaped, so they may contain any text.
+func SetLinkLabels(flowchart []byte, links []Link, labels map[int]string) []byte {
+	ret := make([]byte, 0, len(flowchart))
+	last := 0
+
+	for _, link := range links {
+		label, ok := labels[link.Index]
+		if !ok {
+			continue
+		}
+
+		ret = append(ret, flowchart[last:link.Start]...)
+		ret = append(ret, link.Arrow...)
+		ret = append(ret, '|', '"')
+		ret = append(ret, escapeLabel(label)...)
+		ret = append(ret, '"', '|')
+		last = link.End
+	}
+
+	return append(ret, flowchart[last:]...)
+}
+
+var labelReplacer = strings.NewReplacer(
+	`"`, "#quot;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// escapeLabel escapes the label to be used in a quoted label.
+func escapeLabel(label string) string {
+	return labelReplacer.Replace(label)
+}
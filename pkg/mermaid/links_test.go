@@ -0,0 +1,81 @@
+package mermaid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+
+	flowchart := `---
+title: example
+---
+flowchart TD
+  %% A --> commented
+  subgraph cluster1[Cluster 1]
+    a1[out-first-secret] --> a2("a --> b")
+  end
+  style a1 fill:#f9f
+  a1 -- copies --> b1 & b2
+  b1-.->|async| c:::someclass
+  c e1@==> d{{decision}} --- e
+  f -. dotted .-> g; g --o h
+  my-node<-->i
+`
+
+	type link struct {
+		from, to, arrow, label string
+	}
+
+	expected := []link{
+		{"a1", "a2", "-->", ""},
+		{"a1", "b1", "-->", "copies"},
+		{"a1", "b2", "-->", "copies"},
+		{"b1", "c", "-.->", "async"},
+		{"c", "d", "==>", ""},
+		{"d", "e", "---", ""},
+		{"f", "g", "-.->", "dotted"},
+		{"g", "h", "--o", ""},
+		{"my-node", "i", "<-->", ""},
+	}
+
+	links := Links([]byte(flowchart))
+	require.Len(t, links, len(expected))
+
+	for i, l := range links {
+		require.Equal(t, i, l.Index)
+		require.Equal(t, expected[i], link{l.From, l.To, l.Arrow, l.Label}, "link %d", i)
+	}
+
+	require.Equal(t, "-- copies -->", flowchart[links[1].Start:links[1].End])
+	require.Equal(t, "-.->|async|", flowchart[links[3].Start:links[3].End])
+	require.Equal(t, "==>", flowchart[links[4].Start:links[4].End])
+}
+
+func TestSetLinkLabels(t *testing.T) {
+	t.Parallel()
+
+	flowchart := []byte("flowchart TD\n  a -- old --> b\n  b -.-> c\n  c --> d\n")
+	links := Links(flowchart)
+
+	require.Equal(t,
+		"flowchart TD\n  a -->|\"new\"| b\n  b -.-> c\n  c -->|\"label\"| d\n",
+		string(SetLinkLabels(flowchart, links, map[int]string{0: "new", 2: "label"})),
+	)
+
+	// special characters in labels must not break the flowchart
+	labeled := SetLinkLabels(flowchart, links, map[int]string{0: "a | \"quoted\"\nline"})
+	require.Equal(t,
+		"flowchart TD\n  a -->|\"a | #quot;quoted#quot;<br>line\"| b\n  b -.-> c\n  c --> d\n",
+		string(labeled),
+	)
+
+	parsed := Links(labeled)
+	require.Len(t, parsed, 3)
+	require.Equal(t, "a", parsed[0].From)
+	require.Equal(t, "b", parsed[0].To)
+	require.Equal(t, `"a | #quot;quoted#quot;<br>line"`, parsed[0].Label)
+	require.Equal(t, "b", parsed[1].From)
+}
@@ -0,0 +1,434 @@
+package mermaid
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// statementKeywords are the keywords of statements that do not contain
+// links.
+var statementKeywords = []string{
+	"flowchart", "graph", "subgraph", "end", "direction",
+	"style", "classDef", "class", "click", "linkStyle",
+	"accTitle", "accDescr",
+}
+
+var (
+	// linkToken matches links like -->, ---, -.->, ==>, <-->, --o or ~~~.
+	linkToken = regexp.MustCompile(`^[<ox]?(?:-{2,}|={2,}|-\.+-|~{3,})[>ox]?`)
+	// textLinkOpen matches the start of links with the label inside the
+	// link, e.g. "-- label -->".
+	textLinkOpen = regexp.MustCompile(`^([<ox]?)(--|==|-\.)\s`)
+	// textLinkClose matches the end of links with the label inside the
+	// link, keyed by the opening of the link.
+	textLinkClose = map[string]*regexp.Regexp{
+		"--": regexp.MustCompile(`\s-{2,}[>ox]?`),
+		"==": regexp.MustCompile(`\s={2,}[>ox]?`),
+		"-.": regexp.MustCompile(`\s\.+-[>ox]?`),
+	}
+)
+
+type parser struct {
+	src []byte
+	pos int
+
+	flowchart *Flowchart
+	// subgraphs is the stack of currently open subgraphs
+	subgraphs []string
+	// seen tracks the IDs of nodes that were already added
+	seen map[string]bool
+}
+
+func (p *parser) parse() {
+	p.skipFrontmatter()
+
+	for p.pos < len(p.src) {
+		p.skipSpace()
+
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		if bytes.HasPrefix(p.src[p.pos:], []byte("%%")) {
+			p.skipStatement()
+			continue
+		}
+
+		switch word := p.peekWord(); {
+		case word == "subgraph":
+			p.parseSubgraph()
+		case word == "end":
+			if len(p.subgraphs) > 0 {
+				p.subgraphs = p.subgraphs[:len(p.subgraphs)-1]
+			}
+
+			p.skipStatement()
+		case slices.Contains(statementKeywords, word):
+			p.skipStatement()
+		default:
+			p.parseStatement()
+		}
+	}
+
+	// Subgraphs can be referenced in links like nodes, possibly before
+	// they are declared.
+	p.flowchart.Nodes = slices.DeleteFunc(p.flowchart.Nodes, func(node Node) bool {
+		return p.flowchart.Subgraph(node.ID) != nil
+	})
+}
+
+// parseSubgraph parses the start of a subgraph, e.g.
+// "subgraph id [title]" or "subgraph title".
+func (p *parser) parseSubgraph() {
+	defer p.skipStatement()
+
+	p.pos += len("subgraph")
+	p.skipInlineSpace()
+
+	start := p.pos
+	end := p.pos
+
+	for end < len(p.src) && p.src[end] != '\n' && p.src[end] != ';' {
+		end++
+	}
+
+	title := strings.Trim(string(bytes.TrimSpace(p.src[start:end])), `"`)
+	subgraph := Subgraph{ID: title, Title: title}
+
+	if id := p.parseID(); id != "" {
+		p.skipInlineSpace()
+
+		switch {
+		case p.pos < len(p.src) && p.src[p.pos] == '[':
+			titleStart, titleEnd := p.pos+1, end
+			// an unclosed title extends to the end of the line
+			if p.skipBracketed() {
+				titleEnd = p.pos - 1
+			}
+
+			subgraph.ID = id
+			subgraph.Title = strings.Trim(strings.TrimSpace(string(p.src[titleStart:titleEnd])), `"`)
+		case p.pos >= end:
+			subgraph.ID = id
+			subgraph.Title = id
+		}
+	}
+
+	if len(p.subgraphs) > 0 {
+		subgraph.Parent = p.subgraphs[len(p.subgraphs)-1]
+	}
+
+	p.flowchart.Subgraphs = append(p.flowchart.Subgraphs, subgraph)
+	p.subgraphs = append(p.subgraphs, subgraph.ID)
+}
+
+// addNode adds the node to the flowchart if it was not seen before.
+func (p *parser) addNode(id string) {
+	if p.seen[id] {
+		return
+	}
+
+	p.seen[id] = true
+
+	node := Node{ID: id}
+	if len(p.subgraphs) > 0 {
+		node.Subgraph = p.subgraphs[len(p.subgraphs)-1]
+	}
+
+	p.flowchart.Nodes = append(p.flowchart.Nodes, node)
+}
+
+// parseStatement parses a statement of nodes and links, e.g.
+// "A[label] --> B & C -.-> D".
+func (p *parser) parseStatement() {
+	defer p.skipStatement()
+
+	from := p.parseNodeGroup()
+	if len(from) == 0 {
+		return
+	}
+
+	for {
+		p.skipInlineSpace()
+
+		link, ok := p.parseLink()
+		if !ok {
+			return
+		}
+
+		p.skipInlineSpace()
+
+		to := p.parseNodeGroup()
+		if len(to) == 0 {
+			return
+		}
+
+		for _, f := range from {
+			for _, t := range to {
+				l := link
+				l.Index = len(p.flowchart.Links)
+				l.From = f
+				l.To = t
+				p.flowchart.Links = append(p.flowchart.Links, l)
+			}
+		}
+
+		from = to
+	}
+}
+
+// parseNodeGroup parses nodes joined by &, e.g. "A & B[label]".
+func (p *parser) parseNodeGroup() []string {
+	var ids []string
+
+	for {
+		id := p.parseNode()
+		if id == "" {
+			return ids
+		}
+
+		p.addNode(id)
+		ids = append(ids, id)
+
+		start := p.pos
+		p.skipInlineSpace()
+
+		if p.pos >= len(p.src) || p.src[p.pos] != '&' {
+			p.pos = start
+			return ids
+		}
+
+		p.pos++
+		p.skipInlineSpace()
+	}
+}
+
+// parseNode parses a node reference including its shape and class and
+// returns its ID.
+func (p *parser) parseNode() string {
+	id := p.parseID()
+	if id == "" {
+		return ""
+	}
+
+	if p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '@' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+			p.pos++
+			p.skipBracketed()
+		case c == '>':
+			p.skipUntil(']')
+		case c == '[' || c == '(' || c == '{':
+			p.skipBracketed()
+		}
+	}
+
+	if bytes.HasPrefix(p.src[p.pos:], []byte(":::")) {
+		p.pos += 3
+		p.parseID()
+	}
+
+	return id
+}
+
+// parseLink parses a link with an optional edge ID and label.
+func (p *parser) parseLink() (Link, bool) {
+	start := p.pos
+
+	// edge IDs, e.g. "A e1@--> B"
+	if id := p.parseID(); id == "" || p.pos >= len(p.src) || p.src[p.pos] != '@' {
+		p.pos = start
+	} else {
+		p.pos++
+	}
+
+	link := Link{Start: p.pos}
+	rest := p.src[p.pos:]
+
+	if m := textLinkOpen.FindSubmatch(rest); m != nil {
+		closeLoc := textLinkClose[string(m[2])].FindIndex(rest[len(m[0]):])
+		if closeLoc == nil {
+			p.pos = start
+			return Link{}, false
+		}
+
+		closing := rest[len(m[0])+closeLoc[0]+1 : len(m[0])+closeLoc[1]]
+		link.Label = string(bytes.TrimSpace(rest[len(m[0]) : len(m[0])+closeLoc[0]]))
+		link.Arrow = string(m[1]) + string(closing)
+
+		if string(m[2]) == "-." {
+			link.Arrow = string(m[1]) + "-" + string(closing)
+		}
+
+		p.pos += len(m[0]) + closeLoc[1]
+		link.End = p.pos
+
+		return link, true
+	}
+
+	loc := linkToken.FindIndex(rest)
+	if loc == nil {
+		p.pos = start
+		return Link{}, false
+	}
+
+	end := loc[1]
+	// A trailing o or x followed by an identifier is the start of the
+	// next node rather than an arrow head.
+	if last := rest[end-1]; (last == 'o' || last == 'x') && end < len(rest) && isIDByte(rest[end]) {
+		end--
+	}
+
+	link.Arrow = string(rest[:end])
+	p.pos += end
+	link.End = p.pos
+
+	labelStart := p.pos
+	p.skipInlineSpace()
+
+	if p.pos < len(p.src) && p.src[p.pos] == '|' {
+		i := bytes.IndexByte(p.src[p.pos+1:], '|')
+		// quoted labels may contain pipes
+		if bytes.HasPrefix(p.src[p.pos+1:], []byte{'"'}) {
+			if q := bytes.Index(p.src[p.pos+2:], []byte(`"|`)); q >= 0 {
+				i = q + 2
+			}
+		}
+
+		if i >= 0 {
+			link.Label = string(bytes.TrimSpace(p.src[p.pos+1 : p.pos+1+i]))
+			p.pos += i + 2
+			link.End = p.pos
+
+			return link, true
+		}
+	}
+
+	p.pos = labelStart
+
+	return link, true
+}
+
+// parseID parses a node ID.
+func (p *parser) parseID() string {
+	start := p.pos
+
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRune(p.src[p.pos:])
+
+		switch {
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		case r == '-' && p.pos+1 < len(p.src) && isIDByte(p.src[p.pos+1]) && p.pos > start:
+			// dashes are allowed within IDs but not as start of a link
+		default:
+			return string(p.src[start:p.pos])
+		}
+
+		p.pos += size
+	}
+
+	return string(p.src[start:p.pos])
+}
+
+func isIDByte(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// peekWord returns the word at the current position without consuming
+// it.
+func (p *parser) peekWord() string {
+	end := p.pos
+	for end < len(p.src) && (unicode.IsLetter(rune(p.src[end])) || unicode.IsDigit(rune(p.src[end]))) {
+		end++
+	}
+
+	return string(p.src[p.pos:end])
+}
+
+// skipBracketed skips a bracketed text like node shapes, taking nested
+// brackets and quotes into account. It reports whether the closing
+// bracket was found before the end of the line.
+func (p *parser) skipBracketed() bool {
+	depth := 0
+
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '"':
+			p.pos++
+			p.skipUntil('"')
+
+			continue
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return true
+			}
+		case '\n':
+			return false
+		}
+
+		p.pos++
+	}
+
+	return false
+}
+
+// skipUntil skips past the next occurrence of c on the current line.
+func (p *parser) skipUntil(c byte) {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+		if p.src[p.pos-1] == c {
+			return
+		}
+	}
+}
+
+// skipStatement skips to the end of the current statement.
+func (p *parser) skipStatement() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '"':
+			p.pos++
+			p.skipUntil('"')
+
+			continue
+		case '\n', ';':
+			p.pos++
+			return
+		}
+
+		p.pos++
+	}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && (unicode.IsSpace(rune(p.src[p.pos])) || p.src[p.pos] == ';') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipInlineSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipFrontmatter skips a YAML frontmatter block at the start of the
+// flowchart.
+func (p *parser) skipFrontmatter() {
+	if !bytes.HasPrefix(p.src, []byte("---\n")) {
+		return
+	}
+
+	if i := bytes.Index(p.src[4:], []byte("\n---")); i >= 0 {
+		p.pos = 4 + i + 4
+		p.skipStatement()
+	}
+}
@@ -0,0 +1,41 @@
+package mkl
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/mermaid"
+)
+
+// bindingWarnings cross-checks the configuration against the diagram
+// and returns warnings about configured nodes and edges that are not
+// in the diagram and diagram nodes without configuration.
+func bindingWarnings(config *mklv1alpha1.Config, flowchart *mermaid.Flowchart) []string {
+	var warnings []string
+
+	for _, name := range slices.Sorted(maps.Keys(config.Nodes)) {
+		// subgraphs can be styled like nodes
+		if flowchart.Node(name) == nil && flowchart.Subgraph(name) == nil {
+			warnings = append(warnings, fmt.Sprintf("node %q is configured but not in the diagram", name))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Edges)) {
+		edge := config.Edges[name]
+		if !slices.ContainsFunc(flowchart.Links, func(link mermaid.Link) bool {
+			return link.From == edge.From && link.To == edge.To
+		}) {
+			warnings = append(warnings, fmt.Sprintf("edge %q is configured but the diagram has no link from %q to %q", name, edge.From, edge.To))
+		}
+	}
+
+	for _, node := range flowchart.Nodes {
+		if _, ok := config.Nodes[node.ID]; !ok {
+			warnings = append(warnings, fmt.Sprintf("diagram node %q has no configuration", node.ID))
+		}
+	}
+
+	return warnings
+}
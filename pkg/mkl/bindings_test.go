@@ -0,0 +1,39 @@
+package mkl
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/mermaid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingWarnings(t *testing.T) {
+	t.Parallel()
+
+	flowchart := mermaid.Parse([]byte(`flowchart TD
+  subgraph cluster
+    a --> b
+  end
+  b --> c
+`))
+
+	config := &mklv1alpha1.Config{
+		Nodes: map[string]mklv1alpha1.Node{
+			"cluster": {},
+			"a":       {},
+			"b":       {},
+			"renamed": {},
+		},
+		Edges: map[string]mklv1alpha1.Edge{
+			"ab": {From: "a", To: "b"},
+			"ba": {From: "b", To: "a"},
+		},
+	}
+
+	require.Equal(t, []string{
+		`node "renamed" is configured but not in the diagram`,
+		`edge "ba" is configured but the diagram has no link from "b" to "a"`,
+		`diagram node "c" has no configuration`,
+	}, bindingWarnings(config, flowchart))
+}
@@ -0,0 +1,367 @@
+package mkl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/discovery"
+	"github.com/ntnn/mermaid-kube-live/pkg/generator"
+	"github.com/ntnn/mermaid-kube-live/pkg/mermaid"
+	"github.com/ntnn/mermaid-kube-live/pkg/styler"
+	"github.com/ntnn/mermaid-kube-live/pkg/webserver"
+)
+
+// diagram is a diagram with its own configuration, styler and
+// discoverer.
+type diagram struct {
+	name        string
+	configPath  string
+	diagramPath string
+	logger      logr.Logger
+
+	styler *styler.Styler
+
+	diagramLock sync.RWMutex
+	diagram     []byte
+	// applied is the configuration applied to the styler, including
+	// discovered nodes.
+	applied *mklv1alpha1.Config
+
+	discoverer *discovery.Discoverer
+
+	configLock sync.Mutex
+	// embedded are the configurations embedded in the diagram.
+	embedded [][]byte
+	// config is the last loaded configuration without discovered
+	// nodes.
+	config *mklv1alpha1.Config
+
+	// changes is notified whenever the diagram file changed.
+	changes chan struct{}
+}
+
+func newDiagram(opts Diagram, logger logr.Logger) *diagram {
+	if opts.Name != "" {
+		logger = logger.WithValues("diagram", opts.Name)
+	}
+
+	return &diagram{
+		name:        opts.Name,
+		configPath:  opts.ConfigPath,
+		diagramPath: opts.DiagramPath,
+		logger:      logger,
+		changes:     make(chan struct{}, 1),
+	}
+}
+
+// run updates the served diagram whenever it or its styling changes
+// until the context is canceled.
+func (d *diagram) run(ctx context.Context, web *webserver.Diagram, updateInterval time.Duration) {
+	var (
+		rendered []byte
+		warnings []string
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.changes:
+		case <-d.styler.Changes():
+		case <-d.discoverer.Changes():
+			d.configLock.Lock()
+			if err := d.applyConfig(ctx); err != nil {
+				d.logger.Error(err, "failed to apply discovered nodes")
+			}
+			d.configLock.Unlock()
+		}
+
+		diagram, err := d.render()
+		if err != nil {
+			d.logger.Error(err, "failed to render diagram")
+		} else if !bytes.Equal(diagram, rendered) {
+			rendered = diagram
+			web.Update(diagram)
+			d.logger.V(2).Info("diagram updated")
+		}
+
+		if newWarnings := d.bindingWarnings(); !slices.Equal(newWarnings, warnings) {
+			warnings = newWarnings
+			for _, warning := range warnings {
+				d.logger.Info("diagram and configuration do not match", "warning", warning)
+			}
+			web.UpdateWarnings(warnings)
+		}
+
+		web.UpdateStatus(string(d.status()))
+
+		// Throttle updates, changes in the meantime are coalesced by
+		// the notification channels.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(updateInterval):
+		}
+	}
+}
+
+// start watches the diagram and configuration.
+func (d *diagram) start(ctx context.Context) error {
+	if err := d.watchDiagram(ctx); err != nil {
+		return fmt.Errorf("error watching diagram file: %w", err)
+	}
+
+	if err := d.watchConfig(ctx); err != nil {
+		return fmt.Errorf("error watching config file: %w", err)
+	}
+
+	return nil
+}
+
+// render combines the diagram with the current styling.
+func (d *diagram) render() ([]byte, error) {
+	d.diagramLock.RLock()
+	diagram := d.diagram
+	d.diagramLock.RUnlock()
+
+	styled, err := d.styler.StyleDiagram(diagram)
+	if err != nil {
+		return nil, fmt.Errorf("failed to style diagram: %w", err)
+	}
+
+	return styled, nil
+}
+
+// status returns the most significant status of the nodes of the
+// diagram.
+func (d *diagram) status() mklv1alpha1.ResourceStatus {
+	d.diagramLock.RLock()
+	applied := d.applied
+	d.diagramLock.RUnlock()
+
+	if applied == nil {
+		return mklv1alpha1.ResourceUnknown
+	}
+
+	statuses := d.styler.Statuses()
+
+	return mklv1alpha1.MostSignificant(applied.Style.Precedence, slices.Collect(maps.Values(statuses))...)
+}
+
+// nodes returns the state of the nodes for the web server.
+func (d *diagram) nodes() []webserver.Node {
+	states := d.styler.NodeStates()
+
+	nodes := make([]webserver.Node, 0, len(states))
+	for nodeName, state := range states {
+		if state.Clusters == nil {
+			state.Clusters = []string{}
+		}
+
+		nodes = append(nodes, webserver.Node{
+			Name:               nodeName,
+			Status:             string(state.Status),
+			Label:              state.Label,
+			Resources:          state.Resources,
+			Clusters:           state.Clusters,
+			LastTransitionTime: state.LastTransitionTime,
+		})
+	}
+
+	return nodes
+}
+
+// bindingWarnings cross-checks the applied configuration against the
+// diagram.
+func (d *diagram) bindingWarnings() []string {
+	d.diagramLock.RLock()
+	defer d.diagramLock.RUnlock()
+
+	if d.applied == nil {
+		return nil
+	}
+
+	return bindingWarnings(d.applied, mermaid.Parse(d.diagram))
+}
+
+func (d *diagram) notifyChange() {
+	select {
+	case d.changes <- struct{}{}:
+	default:
+		// a notification is already pending
+	}
+}
+
+func (d *diagram) watchDiagram(ctx context.Context) error {
+	if d.diagramPath == "" {
+		return nil
+	}
+
+	return d.watchFile(ctx, d.diagramPath, func() error {
+		embedded, diagram, err := readDiagram(d.diagramPath)
+		if err != nil {
+			return err
+		}
+
+		d.diagramLock.Lock()
+		d.diagram = diagram
+		d.diagramLock.Unlock()
+		d.logger.V(2).Info("diagram file updated", "file", d.diagramPath, "content", string(diagram))
+		d.notifyChange()
+
+		d.configLock.Lock()
+		changed := !slices.EqualFunc(d.embedded, embedded, bytes.Equal)
+		d.embedded = embedded
+		d.configLock.Unlock()
+
+		// The config is always loaded initially when there is no
+		// config file.
+		if !changed && d.configPath != "" {
+			return nil
+		}
+
+		if _, err := d.loadConfig(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// readDiagram reads the diagram file and returns the configurations
+// embedded in it and the diagram without them.
+func readDiagram(path string) ([][]byte, []byte, error) {
+	rawDiagram, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read diagram file %s: %w", path, err)
+	}
+
+	embedded, diagram := mermaid.Embedded(rawDiagram, "mkl")
+
+	return embedded, diagram, nil
+}
+
+func (d *diagram) watchConfig(ctx context.Context) error {
+	if d.configPath == "" {
+		return nil
+	}
+
+	return d.watchPaths(ctx, d.configPath, func() ([]string, error) {
+		return d.loadConfig(ctx)
+	})
+}
+
+// loadConfig loads the configuration file and the configuration
+// embedded in the diagram and updates the styler.
+// It returns the paths of the loaded configuration files.
+func (d *diagram) loadConfig(ctx context.Context) ([]string, error) {
+	if d.styler == nil {
+		return nil, errors.New("styler is not initialized")
+	}
+
+	d.configLock.Lock()
+	defer d.configLock.Unlock()
+
+	config, paths, err := d.load(ctx)
+	if err != nil {
+		return paths, err
+	}
+
+	d.config = config
+	d.discoverer.UpdateConfig(config.Discovery)
+
+	if err := d.applyConfig(ctx); err != nil {
+		return paths, err
+	}
+
+	d.logger.V(2).Info("config updated", "paths", paths, "content", config)
+
+	return paths, nil
+}
+
+// load loads and validates the configuration file and the
+// configuration embedded in the diagram.
+// The caller must hold the configLock.
+func (d *diagram) load(ctx context.Context) (*mklv1alpha1.Config, []string, error) {
+	loader := mklv1alpha1.NewLoader()
+
+	if d.configPath != "" {
+		if err := loader.LoadPath(d.configPath); err != nil {
+			return nil, nil, fmt.Errorf("failed to load config %s: %w", d.configPath, err)
+		}
+	}
+
+	for i, embedded := range d.embedded {
+		source := fmt.Sprintf("%s (embedded config %d)", d.diagramPath, i+1)
+		if err := loader.LoadEmbedded(source, embedded); err != nil {
+			return nil, nil, fmt.Errorf("failed to load embedded config: %w", err)
+		}
+	}
+
+	config, paths, err := loader.Config()
+	if err != nil {
+		return nil, paths, err
+	}
+
+	if err := config.Validate(ctx); err != nil {
+		return nil, paths, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, paths, nil
+}
+
+// applyConfig merges the loaded configuration with the discovered
+// nodes and updates the styler and the generated diagram.
+// The caller must hold the configLock.
+func (d *diagram) applyConfig(ctx context.Context) error {
+	if d.config == nil {
+		// not loaded yet
+		return nil
+	}
+
+	config := d.config.DeepCopy()
+
+	discovered := d.discoverer.Nodes()
+	for _, name := range slices.Sorted(maps.Keys(discovered)) {
+		if _, ok := config.Nodes[name]; ok {
+			d.logger.Info("discovered node conflicts with configured node, skipping", "node", name)
+			continue
+		}
+
+		if config.Nodes == nil {
+			config.Nodes = map[string]mklv1alpha1.Node{}
+		}
+
+		config.Nodes[name] = discovered[name]
+	}
+
+	if err := d.styler.UpdateConfig(ctx, config); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+
+	d.diagramLock.Lock()
+	d.applied = config
+	d.diagramLock.Unlock()
+	d.notifyChange()
+
+	if d.diagramPath == "" {
+		diagram := generator.Generate(config)
+
+		d.diagramLock.Lock()
+		d.diagram = diagram
+		d.diagramLock.Unlock()
+		d.logger.V(2).Info("diagram generated", "content", string(diagram))
+		d.notifyChange()
+	}
+
+	return nil
+}
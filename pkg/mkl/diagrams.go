@@ -0,0 +1,95 @@
+package mkl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Diagram is a named diagram with its own configuration.
+type Diagram struct {
+	// Name is the name of the diagram, it is served at /d/<name>/.
+	Name string `json:"name"`
+
+	// ConfigPath is the path to the configuration of the diagram, see
+	// Options.ConfigPath.
+	ConfigPath string `json:"config,omitempty"`
+
+	// DiagramPath is the path to the mermaid diagram file, see
+	// Options.DiagramPath.
+	DiagramPath string `json:"diagram,omitempty"`
+}
+
+// diagramsFile is the file listing the named diagrams.
+type diagramsFile struct {
+	Diagrams []Diagram `json:"diagrams"`
+}
+
+// LoadDiagrams loads the named diagrams from a YAML file. Relative paths
+// are resolved relative to the directory of the file.
+func LoadDiagrams(path string) ([]Diagram, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read diagrams file %s: %w", path, err)
+	}
+
+	var file diagramsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse diagrams file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i, d := range file.Diagrams {
+		file.Diagrams[i].ConfigPath = resolvePath(dir, d.ConfigPath)
+		file.Diagrams[i].DiagramPath = resolvePath(dir, d.DiagramPath)
+	}
+
+	if err := validateDiagrams(file.Diagrams); err != nil {
+		return nil, fmt.Errorf("invalid diagrams file %s: %w", path, err)
+	}
+
+	return file.Diagrams, nil
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// validateDiagrams validates that the diagrams have unique names that
+// can be used in URLs and a configuration or diagram.
+func validateDiagrams(diagrams []Diagram) error {
+	if len(diagrams) == 0 {
+		return errors.New("no diagrams given")
+	}
+
+	seen := map[string]bool{}
+
+	var errs []error
+
+	for i, d := range diagrams {
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Errorf("diagram %d has no name", i+1))
+		case strings.ContainsAny(d.Name, "/?#%"):
+			errs = append(errs, fmt.Errorf("diagram name %q must not contain any of /?#%%", d.Name))
+		case seen[d.Name]:
+			errs = append(errs, fmt.Errorf("diagram %q is defined multiple times", d.Name))
+		}
+
+		seen[d.Name] = true
+
+		if d.ConfigPath == "" && d.DiagramPath == "" {
+			errs = append(errs, fmt.Errorf("diagram %q requires a config or diagram path", d.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
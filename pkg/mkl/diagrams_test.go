@@ -0,0 +1,60 @@
+package mkl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	clustersprovider "sigs.k8s.io/multicluster-runtime/providers/clusters"
+)
+
+func TestLoadDiagrams(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "diagrams.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`diagrams:
+- name: frontend
+  config: frontend/mkl.yaml
+  diagram: /abs/frontend.mermaid
+- name: backend
+  diagram: backend.mermaid
+`), 0o600))
+
+	diagrams, err := LoadDiagrams(path)
+	require.NoError(t, err)
+	require.Equal(t, []Diagram{
+		{Name: "frontend", ConfigPath: filepath.Join(dir, "frontend/mkl.yaml"), DiagramPath: "/abs/frontend.mermaid"},
+		{Name: "backend", DiagramPath: filepath.Join(dir, "backend.mermaid")},
+	}, diagrams)
+
+	require.NoError(t, os.WriteFile(path, []byte(`diagrams:
+- name: frontend
+  config: mkl.yaml
+- name: frontend
+  config: mkl.yaml
+- name: a/b
+- config: mkl.yaml
+`), 0o600))
+
+	_, err = LoadDiagrams(path)
+	require.ErrorContains(t, err, `diagram "frontend" is defined multiple times`)
+	require.ErrorContains(t, err, `diagram name "a/b" must not contain`)
+	require.ErrorContains(t, err, `diagram "a/b" requires a config or diagram path`)
+	require.ErrorContains(t, err, `diagram 4 has no name`)
+}
+
+func TestOptionsValidateDiagrams(t *testing.T) {
+	t.Parallel()
+
+	opts := &Options{
+		Provider:   clustersprovider.New(),
+		ConfigPath: "mkl.yaml",
+		Diagrams:   []Diagram{{Name: "frontend", ConfigPath: "frontend.yaml"}},
+	}
+	require.ErrorContains(t, opts.Validate(), "cannot be used with named diagrams")
+
+	opts.ConfigPath = ""
+	require.NoError(t, opts.Validate())
+}
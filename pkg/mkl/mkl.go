@@ -8,14 +8,14 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/ntnn/mcutils"
-	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/discovery"
 	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"github.com/ntnn/mermaid-kube-live/pkg/recording"
 	"github.com/ntnn/mermaid-kube-live/pkg/styler"
 	"github.com/ntnn/mermaid-kube-live/pkg/webserver"
 	"k8s.io/client-go/rest"
@@ -28,16 +28,34 @@ type Options struct {
 	// Provider is the multicluster provider of clusters to watch.
 	Provider multicluster.Provider
 
-	// ConfigPath is the path to the mermaid-kube-live configuration file.
+	// ConfigPath is the path to the mermaid-kube-live configuration.
+	// It can be a file, a directory or a glob, see
+	// mklv1alpha1.Load.
+	// The configuration can also be embedded in the diagram, in which
+	// case ConfigPath is optional. Embedded and file configurations
+	// are merged.
 	ConfigPath string
 
 	// DiagramPath is the path to the mermaid diagram file.
+	// If not set the diagram is generated from the configuration.
 	DiagramPath string
 
-	// UpdateInterval is the interval at which to update the diagram.
-	// It not set the diagram will be updated every second.
+	// Diagrams are named diagrams with their own configuration,
+	// served at /d/<name>/ with an index of them at the root.
+	// ConfigPath and DiagramPath must be empty if Diagrams are set.
+	Diagrams []Diagram
+
+	// UpdateInterval is the minimum interval between updates of the
+	// diagram. Changes within the interval are coalesced into a single
+	// update.
+	// If not set the diagram will be updated at most every second.
 	UpdateInterval time.Duration
 
+	// RecordPath is the path of a file to record all observed resources
+	// to as JSON lines. The recording can be replayed with
+	// recording.ReplayProvider.
+	RecordPath string
+
 	// Adresss is the address of the webserver.
 	Address string
 
@@ -49,10 +67,11 @@ type Options struct {
 func (o *Options) FlagSet() *flag.FlagSet {
 	fs := flag.NewFlagSet("mkl", flag.ExitOnError)
 
-	fs.StringVar(&o.ConfigPath, "config", "", "Configuration file")
-	fs.StringVar(&o.DiagramPath, "diagram", "", "Diagram file")
-	fs.DurationVar(&o.UpdateInterval, "update-interval", time.Second, "Interval to update the diagram")
+	fs.StringVar(&o.ConfigPath, "config", "", "Configuration file, directory or glob")
+	fs.StringVar(&o.DiagramPath, "diagram", "", "Diagram file, generated from the configuration if not set")
+	fs.DurationVar(&o.UpdateInterval, "update-interval", time.Second, "Minimum interval between diagram updates")
 	fs.StringVar(&o.Address, "address", "localhost:8080", "Address to listen on")
+	fs.StringVar(&o.RecordPath, "record", "", "File to record all observed resources to as JSON lines")
 
 	return fs
 }
@@ -63,12 +82,16 @@ func (o *Options) Validate() error {
 		return errors.New("provider is required")
 	}
 
-	if o.ConfigPath == "" {
-		return errors.New("config path is required")
-	}
+	if len(o.Diagrams) > 0 {
+		if o.ConfigPath != "" || o.DiagramPath != "" {
+			return errors.New("config and diagram path cannot be used with named diagrams")
+		}
 
-	if o.DiagramPath == "" {
-		return errors.New("diagram path is required")
+		if err := validateDiagrams(o.Diagrams); err != nil {
+			return err
+		}
+	} else if o.ConfigPath == "" && o.DiagramPath == "" {
+		return errors.New("config path is required if no diagram path is given")
 	}
 
 	if o.UpdateInterval <= 0 {
@@ -90,11 +113,10 @@ func (o *Options) Validate() error {
 type MKL struct {
 	opts *Options
 
-	web    *webserver.WebServer
-	styler *styler.Styler
+	web *webserver.WebServer
 
-	diagramLock sync.RWMutex
-	diagram     []byte
+	// diagrams share the multicluster manager and multiplexer.
+	diagrams []*diagram
 }
 
 // New creates a new MKL instance with the given options.
@@ -106,6 +128,15 @@ func New(opts *Options) (*MKL, error) {
 	instance := new(MKL)
 	instance.opts = opts
 
+	diagrams := opts.Diagrams
+	if len(diagrams) == 0 {
+		diagrams = []Diagram{{ConfigPath: opts.ConfigPath, DiagramPath: opts.DiagramPath}}
+	}
+
+	for _, d := range diagrams {
+		instance.diagrams = append(instance.diagrams, newDiagram(d, opts.Logger))
+	}
+
 	return instance, nil
 }
 
@@ -116,44 +147,38 @@ func (m *MKL) Run(ctx context.Context) error {
 		return fmt.Errorf("error starting web server: %w", err)
 	}
 
-	if err := m.watchDiagram(ctx); err != nil {
-		return fmt.Errorf("error watching diagram file: %w", err)
+	if err := m.start(ctx); err != nil {
+		return err
 	}
 
-	if err := m.startStyler(ctx); err != nil {
-		return fmt.Errorf("error starting styler: %w", err)
+	wg := &sync.WaitGroup{}
+	for _, d := range m.diagrams {
+		web := m.web.Diagram(d.name)
+		web.SetNodes(d.nodes)
+		wg.Go(func() {
+			d.run(ctx, web, m.opts.UpdateInterval)
+		})
 	}
+	wg.Wait()
 
-	if err := m.watchConfig(ctx); err != nil {
-		return fmt.Errorf("error watching config file: %w", err)
+	return ctx.Err()
+}
+
+// start starts the stylers and watches the diagrams and
+// configurations.
+func (m *MKL) start(ctx context.Context) error {
+	if err := m.startStylers(ctx); err != nil {
+		return fmt.Errorf("error starting styler: %w", err)
 	}
 
-	// TODO instead of updating the diagram on a fixed interval, it
-	// should update it whenever the styler updates
-	// already got handlers for diagram changes, just need another one
-	// for styler changes.
-	for range time.Tick(m.opts.UpdateInterval) {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
+	for _, d := range m.diagrams {
+		if err := d.start(ctx); err != nil {
+			if d.name != "" {
+				return fmt.Errorf("diagram %q: %w", d.name, err)
+			}
 
-		styling, err := m.styler.GetStyling()
-		if err != nil {
-			m.opts.Logger.Error(err, "failed to get styling")
-			continue
+			return err
 		}
-
-		b := strings.Builder{}
-
-		m.diagramLock.RLock()
-		b.Write(m.diagram)
-		m.diagramLock.RUnlock()
-
-		b.WriteString("\n")
-		b.WriteString(styling)
-
-		m.web.UpdateDiagram([]byte(b.String()))
-		// m.opts.Logger.V(2).Info("diagram updated", "content", b.String())
 	}
 
 	return nil
@@ -171,7 +196,9 @@ func (m *MKL) startWebServer(ctx context.Context) error {
 	return nil
 }
 
-func (m *MKL) startStyler(ctx context.Context) error {
+// startStylers starts the multicluster manager and creates the
+// styler and discoverer of each diagram.
+func (m *MKL) startStylers(ctx context.Context) error {
 	mgr, err := mctrl.NewManager(&rest.Config{}, m.opts.Provider, mcutils.SilentManagerOpts(mctrl.Options{
 		Logger: m.opts.Logger.WithName("multicluster-manager"),
 	}))
@@ -184,56 +211,47 @@ func (m *MKL) startStyler(ctx context.Context) error {
 		return fmt.Errorf("error adding multiplexer to manager: %w", err)
 	}
 
-	go func() {
-		if err := mgr.Start(ctx); err != nil {
-			m.opts.Logger.Error(err, "multicluster manager errored")
-		}
-	}()
-
-	st, err := styler.New(mp)
-	if err != nil {
-		return fmt.Errorf("failed to create styler: %w", err)
-	}
-	m.styler = st
-	return nil
-}
+	var recorder *recording.Recorder
 
-func (m *MKL) watchDiagram(ctx context.Context) error {
-	return m.watchFile(ctx, m.opts.DiagramPath, func() error {
-		rawDiagram, err := os.ReadFile(m.opts.DiagramPath)
+	if m.opts.RecordPath != "" {
+		f, err := os.Create(m.opts.RecordPath)
 		if err != nil {
-			return fmt.Errorf("failed to read diagram file %s: %w", m.opts.DiagramPath, err)
+			return fmt.Errorf("failed to create recording: %w", err)
 		}
 
-		m.diagramLock.Lock()
-		m.diagram = rawDiagram
-		m.diagramLock.Unlock()
-		m.opts.Logger.V(2).Info("diagram file updated", "file", m.opts.DiagramPath, "content", string(rawDiagram))
+		recorder = recording.NewRecorder(f)
 
-		return nil
-	})
-}
+		// Reconcilers may still record while the manager stops, the
+		// recorder discards their events once it is closed.
+		go func() {
+			<-ctx.Done()
 
-func (m *MKL) watchConfig(ctx context.Context) error {
-	if m.styler == nil {
-		return errors.New("styler is not initialized")
+			if err := recorder.Close(); err != nil {
+				m.opts.Logger.Error(err, "failed to close recording")
+			}
+		}()
 	}
-	return m.watchFile(ctx, m.opts.ConfigPath, func() error {
-		config, err := mklv1alpha1.ParseFile(m.opts.ConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to parse config file %s: %w", m.opts.ConfigPath, err)
-		}
 
-		if err := config.Validate(ctx); err != nil {
-			return fmt.Errorf("invalid config file %s: %w", m.opts.ConfigPath, err)
+	for _, d := range m.diagrams {
+		d.discoverer = discovery.New(mp, d.name)
+		if err := mgr.Add(d.discoverer); err != nil {
+			return fmt.Errorf("error adding discovery to manager: %w", err)
 		}
 
-		if err := m.styler.UpdateConfig(ctx, config); err != nil {
-			return fmt.Errorf("failed to update config: %w", err)
+		st, err := styler.New(mp, d.name)
+		if err != nil {
+			return fmt.Errorf("failed to create styler: %w", err)
 		}
 
-		m.opts.Logger.V(2).Info("config file updated", "file", m.opts.ConfigPath, "content", config)
+		st.Recorder = recorder
+		d.styler = st
+	}
+
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			m.opts.Logger.Error(err, "multicluster manager errored")
+		}
+	}()
 
-		return nil
-	})
+	return nil
 }
@@ -0,0 +1,274 @@
+package mkl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/generator"
+	"github.com/ntnn/mermaid-kube-live/pkg/mermaid"
+	"github.com/ntnn/mermaid-kube-live/pkg/styler"
+)
+
+// Render connects to the clusters, waits until the resources of all
+// nodes have been observed and returns the styled diagram once.
+func (m *MKL) Render(ctx context.Context, timeout time.Duration) ([]byte, error) {
+	d, err := m.single()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.startSynced(ctx, timeout, func(string) bool { return true }); err != nil {
+		return nil, err
+	}
+
+	return d.render()
+}
+
+// Explain connects to the clusters, waits until the resources of the
+// node have been observed and explains the status of the node.
+func (m *MKL) Explain(ctx context.Context, timeout time.Duration, nodeName string) (styler.Explanation, error) {
+	d, err := m.single()
+	if err != nil {
+		return styler.Explanation{}, err
+	}
+
+	// other nodes, e.g. without matching cluster, must not delay the
+	// explanation
+	if err := m.startSynced(ctx, timeout, func(name string) bool { return name == nodeName }); err != nil {
+		return styler.Explanation{}, err
+	}
+
+	return d.styler.Explain(ctx, nodeName)
+}
+
+// single returns the diagram of the one-shot commands, which do not
+// support named diagrams.
+func (m *MKL) single() (*diagram, error) {
+	if len(m.diagrams) != 1 {
+		return nil, fmt.Errorf("expected a single diagram, got %d", len(m.diagrams))
+	}
+
+	return m.diagrams[0], nil
+}
+
+// syncPollInterval is the interval to check whether all nodes have
+// synced.
+const syncPollInterval = 100 * time.Millisecond
+
+// startSynced starts mkl and waits until the watches of all nodes
+// matching the filter have synced or the timeout expires.
+func (m *MKL) startSynced(ctx context.Context, timeout time.Duration, filter func(nodeName string) bool) error {
+	if err := m.start(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return m.diagrams[0].waitForSync(ctx, filter)
+}
+
+// waitForSync waits until the watches of all nodes matching the filter
+// have synced.
+func (d *diagram) waitForSync(ctx context.Context, filter func(nodeName string) bool) error {
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		unsynced := slices.DeleteFunc(d.styler.Unsynced(), func(nodeName string) bool {
+			return !filter(nodeName)
+		})
+		if len(unsynced) == 0 {
+			return nil
+		}
+
+		// failed nodes are not retried and would only time out
+		syncErrs := d.styler.SyncErrors()
+
+		var errs []error
+
+		for _, nodeName := range unsynced {
+			if err := syncErrs[nodeName]; err != nil {
+				errs = append(errs, fmt.Errorf("node %s: %w", nodeName, err))
+			}
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to sync nodes: %w", errors.Join(errs...))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for nodes to sync: %s", strings.Join(unsynced, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// Wait connects to the clusters and waits until the given nodes have
+// the status or the timeout expires. If no nodes are given all nodes
+// are waited for.
+// Progress is written to the progress writer whenever the nodes that
+// are not yet in the status change.
+func (m *MKL) Wait(ctx context.Context, timeout time.Duration, status mklv1alpha1.ResourceStatus, nodes []string, progress io.Writer) error {
+	d, err := m.single()
+	if err != nil {
+		return err
+	}
+
+	// check the status before connecting so typos fail immediately
+	// instead of after the timeout
+	config, _, err := loadOffline(ctx, m.opts)
+	if err != nil {
+		return err
+	}
+
+	if err := validateStatus(config, status); err != nil {
+		return err
+	}
+
+	if err := m.start(ctx); err != nil {
+		return err
+	}
+
+	statuses := d.styler.Statuses()
+	for _, nodeName := range nodes {
+		if _, ok := statuses[nodeName]; !ok {
+			return fmt.Errorf("node %q is not configured", nodeName)
+		}
+	}
+
+	selected := func(nodeName string) bool {
+		return len(nodes) == 0 || slices.Contains(nodes, nodeName)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := d.waitForSync(ctx, selected); err != nil {
+		return err
+	}
+
+	var last map[string]mklv1alpha1.ResourceStatus
+
+	for {
+		pending := map[string]mklv1alpha1.ResourceStatus{}
+
+		for nodeName, nodeStatus := range d.styler.Statuses() {
+			if selected(nodeName) && nodeStatus != status {
+				pending[nodeName] = nodeStatus
+			}
+		}
+
+		if len(pending) == 0 {
+			fmt.Fprintf(progress, "all nodes are %s\n", status)
+			return nil
+		}
+
+		if !maps.Equal(last, pending) {
+			fmt.Fprintf(progress, "waiting for %d nodes to become %s: %s\n", len(pending), status, formatStatuses(pending))
+		}
+
+		last = pending
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for nodes to become %s: %s", status, formatStatuses(pending))
+		case <-d.styler.Changes():
+		}
+	}
+}
+
+// validateStatus returns an error if the status is neither a builtin
+// status nor styled or ranked in the configuration, as no node can
+// reach it.
+func validateStatus(config *mklv1alpha1.Config, status mklv1alpha1.ResourceStatus) error {
+	known := slices.Clone(mklv1alpha1.DefaultPrecedence)
+	known = append(known, slices.Collect(maps.Keys(config.Style.Status))...)
+	known = append(known, config.Style.Precedence...)
+
+	if slices.Contains(known, status) {
+		return nil
+	}
+
+	slices.Sort(known)
+	known = slices.Compact(known)
+
+	formatted := make([]string, len(known))
+	for i, k := range known {
+		formatted[i] = string(k)
+	}
+
+	return fmt.Errorf("unknown status %q, must be one of: %s", status, strings.Join(formatted, ", "))
+}
+
+// formatStatuses formats the statuses of nodes sorted by node name.
+func formatStatuses(statuses map[string]mklv1alpha1.ResourceStatus) string {
+	formatted := make([]string, 0, len(statuses))
+	for _, nodeName := range slices.Sorted(maps.Keys(statuses)) {
+		formatted = append(formatted, fmt.Sprintf("%s (%s)", nodeName, statuses[nodeName]))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// Validate loads and validates the configuration and the diagram of
+// the options without connecting to any clusters.
+// It returns warnings about mismatches between the configuration and
+// the diagram.
+func Validate(ctx context.Context, opts *Options) ([]string, error) {
+	config, diagram, err := loadOffline(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DiagramPath == "" {
+		diagram = generator.Generate(config)
+	}
+
+	return bindingWarnings(config, mermaid.Parse(diagram)), nil
+}
+
+// LoadConfig loads and validates the configuration of the options,
+// including the configuration embedded in the diagram, without
+// connecting to any clusters.
+func LoadConfig(ctx context.Context, opts *Options) (*mklv1alpha1.Config, error) {
+	config, _, err := loadOffline(ctx, opts)
+	return config, err
+}
+
+// loadOffline loads the configuration and the diagram of the options.
+// The diagram is nil if the options have no diagram path.
+func loadOffline(ctx context.Context, opts *Options) (*mklv1alpha1.Config, []byte, error) {
+	if opts.ConfigPath == "" && opts.DiagramPath == "" {
+		return nil, nil, errors.New("config path is required if no diagram path is given")
+	}
+
+	d := newDiagram(Diagram{ConfigPath: opts.ConfigPath, DiagramPath: opts.DiagramPath}, opts.Logger)
+
+	var diagram []byte
+
+	if opts.DiagramPath != "" {
+		embedded, raw, err := readDiagram(opts.DiagramPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		d.embedded = embedded
+		diagram = raw
+	}
+
+	config, _, err := d.load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, diagram, nil
+}
@@ -0,0 +1,72 @@
+package mkl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	diagramPath := filepath.Join(dir, "diagram.mermaid")
+	diagram := `---
+mkl:
+  nodes:
+    secret:
+      selector:
+        clusterName: cluster1
+        gvk:
+          version: v1
+          kind: Secret
+        name: secret
+    missing:
+      selector:
+        clusterName: cluster1
+        gvk:
+          version: v1
+          kind: Secret
+        name: missing
+---
+flowchart TD
+  secret --> other
+`
+	require.NoError(t, os.WriteFile(diagramPath, []byte(diagram), 0o600))
+
+	warnings, err := Validate(t.Context(), &Options{DiagramPath: diagramPath})
+	require.NoError(t, err)
+	require.Len(t, warnings, 2)
+
+	require.NoError(t, os.WriteFile(diagramPath, []byte("---\nmkl:\n  nodes:\n    secret: {}\n---\nflowchart TD\n  secret\n"), 0o600))
+
+	_, err = Validate(t.Context(), &Options{DiagramPath: diagramPath})
+	require.Error(t, err)
+}
+
+func TestFormatStatuses(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "a (pending), b (absent)", formatStatuses(map[string]mklv1alpha1.ResourceStatus{
+		"b": mklv1alpha1.ResourceAbsent,
+		"a": mklv1alpha1.ResourcePending,
+	}))
+}
+
+func TestValidateStatus(t *testing.T) {
+	t.Parallel()
+
+	config := &mklv1alpha1.Config{
+		Style: mklv1alpha1.Style{
+			Status: map[mklv1alpha1.ResourceStatus]string{"crashloop": "fill:red"},
+		},
+	}
+
+	require.NoError(t, validateStatus(config, mklv1alpha1.ResourceHealthy))
+	require.NoError(t, validateStatus(config, "crashloop"))
+	require.EqualError(t, validateStatus(config, "healty"),
+		`unknown status "healty", must be one of: absent, crashloop, degraded, failed, healthy, pending, terminating, unknown`)
+}
@@ -4,38 +4,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/fsnotify/fsnotify"
 )
 
 // watchFile watches a file for changes and calls the provided function
 // whenever a change occurs.
-func (m *MKL) watchFile(ctx context.Context, filePath string, fn func() error) error { //nolint:cyclop
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+func (d *diagram) watchFile(ctx context.Context, filePath string, fn func() error) error {
+	if fn == nil {
+		return errors.New("file hook function cannot be nil")
 	}
 
-	// Watching the directory instead of the file itself to handle cases
-	// where the file is replaced (e.g., by an editor).
-	parentDir := filepath.Dir(filePath)
-	if err := watcher.Add(parentDir); err != nil {
-		return fmt.Errorf("failed to watch parent directory %q of %q: %w", parentDir, filePath, err)
-	}
+	return d.watchPaths(ctx, filePath, func() ([]string, error) {
+		return []string{filePath}, fn()
+	})
+}
 
+// watchPaths watches the paths returned by fn for changes and calls fn
+// whenever a change occurs. Paths can be files, directories or globs.
+// The watched paths are updated with the paths returned by each call of
+// fn, so e.g. newly included files are picked up.
+func (d *diagram) watchPaths(ctx context.Context, name string, fn func() ([]string, error)) error { //nolint:cyclop
 	if fn == nil {
 		return errors.New("file hook function cannot be nil")
 	}
 
-	if err := fn(); err != nil {
-		return fmt.Errorf("initial file hook run failed %q: %w", filePath, err)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	paths, err := fn()
+	if err != nil {
+		watcher.Close() //nolint:errcheck
+		return fmt.Errorf("initial file hook run failed %q: %w", name, err)
+	}
+
+	if err := setWatchedPaths(watcher, paths); err != nil {
+		watcher.Close() //nolint:errcheck
+		return err
 	}
 
 	go func() {
 		defer func() {
 			if err := watcher.Close(); err != nil {
-				m.opts.Logger.Error(err, "failed to close watcher")
+				d.logger.Error(err, "failed to close watcher")
 			}
 		}()
 
@@ -47,22 +64,123 @@ func (m *MKL) watchFile(ctx context.Context, filePath string, fn func() error) e
 				if !ok {
 					return
 				}
-				if filepath.Clean(e.Name) != filepath.Clean(filePath) {
+				if !slices.ContainsFunc(paths, func(path string) bool { return pathMatches(path, e.Name) }) {
 					continue
 				}
-				m.opts.Logger.V(2).Info("file event", "event", e, "file", filePath)
+				d.logger.V(2).Info("file event", "event", e, "file", name)
 
-				if err := fn(); err != nil {
-					m.opts.Logger.Error(err, "file hook function failed", "file", filePath)
+				newPaths, err := fn()
+				if err != nil {
+					d.logger.Error(err, "file hook function failed", "file", name)
+				}
+				// Keep watching the previous paths if the hook failed
+				// without returning paths, e.g. due to a syntax error.
+				if len(newPaths) == 0 {
+					continue
+				}
+
+				paths = newPaths
+				if err := setWatchedPaths(watcher, paths); err != nil {
+					d.logger.Error(err, "failed to update watched paths", "file", name)
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
-				m.opts.Logger.Error(err, "error watching file", "file", filePath)
+				d.logger.Error(err, "error watching file", "file", name)
 			}
 		}
 	}()
 
 	return nil
 }
+
+// setWatchedPaths updates the watcher to watch the directories of the
+// given paths.
+// Directories are watched instead of the files themselves to handle
+// cases where the file is replaced (e.g., by an editor).
+func setWatchedPaths(watcher *fsnotify.Watcher, paths []string) error {
+	var dirs []string
+
+	for _, path := range paths {
+		for _, dir := range watchedDirs(path) {
+			dir = filepath.Clean(dir)
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	for _, dir := range watcher.WatchList() {
+		if !slices.Contains(dirs, dir) {
+			if err := watcher.Remove(dir); err != nil {
+				return fmt.Errorf("failed to stop watching directory %q: %w", dir, err)
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch directory %q: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// watchedDirs returns the directories to watch for changes to the
+// path.
+// For globs with wildcards in the directory part these are the
+// existing directories matching the directory part on each level and
+// the longest directory prefix without wildcards, so new directories
+// are picked up.
+func watchedDirs(path string) []string {
+	if !isGlob(path) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return []string{path}
+		}
+
+		return []string{filepath.Dir(path)}
+	}
+
+	var dirs []string
+
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if !isGlob(dir) {
+			return append(dirs, dir)
+		}
+
+		matches, _ := filepath.Glob(dir)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// pathMatches returns true if a change to file affects the watched
+// path. A file matches if it is the path itself, is within the path
+// or matches the path as a glob. For globs a file also matches if it
+// matches the directory part of the glob on any level, e.g. a new
+// directory that may contain matching files.
+func pathMatches(path, file string) bool {
+	path = filepath.Clean(path)
+	file = filepath.Clean(file)
+
+	if path == file || filepath.Dir(file) == path {
+		return true
+	}
+
+	for pattern := path; isGlob(pattern); pattern = filepath.Dir(pattern) {
+		if matched, _ := filepath.Match(pattern, file); matched {
+			return true
+		}
+	}
+
+	return false
+}
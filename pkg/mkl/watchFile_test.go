@@ -0,0 +1,61 @@
+package mkl
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestWatchPathsGlobDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a", "nodes.yaml"), nil, 0o600))
+
+	glob := filepath.Join(dir, "*", "nodes.yaml")
+
+	var files atomic.Value
+
+	d := newDiagram(Diagram{}, logr.Discard())
+	require.NoError(t, d.watchPaths(t.Context(), glob, func() ([]string, error) {
+		matches, err := filepath.Glob(glob)
+		files.Store(matches)
+
+		return []string{glob}, err
+	}))
+	require.Equal(t, []string{filepath.Join(dir, "a", "nodes.yaml")}, files.Load())
+
+	// files in new directories matching the glob are picked up
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "b"), 0o755))
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b", "nodes.yaml"), nil, 0o600))
+		return len(files.Load().([]string)) == 2 //nolint:forcetypeassert
+	}, wait.ForeverTestTimeout, 100*time.Millisecond)
+}
+
+func TestPathMatches(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		path, file string
+		matches    bool
+	}{
+		{"config.yaml", "config.yaml", true},
+		{"configs", "configs/a.yaml", true},
+		{"configs/*.yaml", "configs/a.yaml", true},
+		{"configs/*.yaml", "other/a.yaml", false},
+		{"g/*/nodes.yaml", "g/a/nodes.yaml", true},
+		{"g/*/nodes.yaml", "g/a", true},
+		{"g/*/nodes.yaml", "g/a/edges.yaml", false},
+		{"g/*/nodes.yaml", "h/a", false},
+	} {
+		require.Equal(t, tc.matches, pathMatches(tc.path, tc.file), "%s %s", tc.path, tc.file)
+	}
+}
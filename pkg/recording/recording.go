@@ -0,0 +1,155 @@
+// Package recording records the resources observed by mermaid-kube-live
+// as JSONL and replays recordings as clusters.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Event is a resource observed in a cluster at a point in time.
+type Event struct {
+	// Time is the time the resource was observed.
+	Time time.Time `json:"time"`
+	// Cluster is the name of the cluster.
+	Cluster string `json:"cluster"`
+	// APIVersion is the API version of the resource.
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+	// Namespace is the namespace of the resource.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the resource.
+	Name string `json:"name"`
+	// Object is the resource. It is nil if the resource was deleted.
+	Object *unstructured.Unstructured `json:"object,omitempty"`
+}
+
+// GroupVersionKind returns the GroupVersionKind of the resource.
+func (e Event) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(e.APIVersion, e.Kind)
+}
+
+// Recorder writes events as JSON lines.
+// The same resource is usually observed multiple times, e.g. by
+// multiple nodes selecting it, so events are only written if the
+// resource changed since the last event.
+type Recorder struct {
+	lock    sync.Mutex
+	w       io.Writer
+	closed  bool
+	encoder *json.Encoder
+	now     func() time.Time
+	// last is the resource version of the last event for each
+	// resource, empty if the resource was deleted
+	last map[string]string
+}
+
+// NewRecorder creates a new Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:       w,
+		encoder: json.NewEncoder(w),
+		now:     time.Now,
+		last:    make(map[string]string),
+	}
+}
+
+// Record records that the resource was observed in the cluster.
+// A nil object records that the resource was deleted.
+func (r *Recorder) Record(cluster string, gvk schema.GroupVersionKind, namespace, name string, obj *unstructured.Unstructured) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		// resources observed while shutting down
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s", cluster, gvk, namespace, name)
+
+	version := ""
+	if obj != nil {
+		version = obj.GetResourceVersion()
+	}
+
+	if last, ok := r.last[key]; ok && last == version {
+		return nil
+	}
+
+	if _, ok := r.last[key]; !ok && obj == nil {
+		// a deletion of a resource that was never observed
+		return nil
+	}
+
+	r.last[key] = version
+
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+
+	return r.encoder.Encode(Event{
+		Time:       r.now(),
+		Cluster:    cluster,
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		Object:     obj,
+	})
+}
+
+// Close stops recording and closes the writer if it is an io.Closer.
+// Events recorded after Close are discarded.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+
+	if closer, ok := r.w.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Read reads the events written by a Recorder.
+func Read(r io.Reader) ([]Event, error) {
+	var events []Event
+
+	scanner := bufio.NewScanner(r)
+	// resources can be larger than the default token size
+	scanner.Buffer(nil, 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse event in line %d: %w", line, err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	return events, nil
+}
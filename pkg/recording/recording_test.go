@@ -0,0 +1,85 @@
+package recording
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var secretGVK = schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+func newSecret(name, resourceVersion string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(secretGVK)
+	u.SetNamespace("default")
+	u.SetName(name)
+	u.SetResourceVersion(resourceVersion)
+
+	return u
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(buf)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	// deletion of a resource that was never observed
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", nil))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", newSecret("a", "1")))
+	// observed again by another node
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", newSecret("a", "1")))
+	require.NoError(t, recorder.Record("cluster2", secretGVK, "default", "a", newSecret("a", "1")))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", newSecret("a", "2")))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", nil))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", nil))
+
+	events, err := Read(buf)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	require.Equal(t, "cluster1", events[0].Cluster)
+	require.Equal(t, secretGVK, events[0].GroupVersionKind())
+	require.Equal(t, "1", events[0].Object.GetResourceVersion())
+	require.Equal(t, "cluster2", events[1].Cluster)
+	require.Equal(t, "2", events[2].Object.GetResourceVersion())
+	require.Nil(t, events[3].Object)
+	require.Equal(t, "a", events[3].Name)
+	require.True(t, events[3].Time.After(events[0].Time))
+}
+
+func TestRecorderClose(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	recorder := NewRecorder(f)
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", newSecret("a", "1")))
+	require.NoError(t, recorder.Close())
+	require.NoError(t, recorder.Close(), "closing twice is a no-op")
+
+	// late events while shutting down are discarded
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "b", newSecret("b", "1")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	events, err := Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "a", events[0].Name)
+}
@@ -0,0 +1,181 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ntnn/mermaid-kube-live/pkg/manifests"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	mctrl "sigs.k8s.io/multicluster-runtime"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// ReplayOptions are the options for the ReplayProvider.
+type ReplayOptions struct {
+	// Path is the path of the recording.
+	Path string
+
+	// Speed is the factor to speed up the replay by, e.g. 10 to replay
+	// an hour in six minutes.
+	// Defaults to 1, which replays the events at the recorded times.
+	Speed float64
+}
+
+// ReplayProvider is a multicluster.Provider replaying a recording.
+// All clusters in the recording are engaged at the start and the
+// events are applied to them at the recorded times relative to the
+// first event. The clusters keep the final state after the replay.
+type ReplayProvider struct {
+	opts   ReplayOptions
+	logger logr.Logger
+	events []Event
+
+	lock     sync.Mutex
+	clusters map[multicluster.ClusterName]*manifests.Cluster
+}
+
+var (
+	_ multicluster.Provider         = &ReplayProvider{}
+	_ multicluster.ProviderRunnable = &ReplayProvider{}
+)
+
+// NewReplayProvider creates a new ReplayProvider and reads the
+// recording.
+func NewReplayProvider(opts ReplayOptions) (*ReplayProvider, error) {
+	if opts.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	if opts.Speed < 0 {
+		return nil, errors.New("speed must not be negative")
+	}
+
+	if opts.Speed == 0 {
+		opts.Speed = 1
+	}
+
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	events, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", opts.Path, err)
+	}
+
+	return &ReplayProvider{
+		opts:     opts,
+		logger:   mctrl.Log.WithName("replay-provider"),
+		events:   events,
+		clusters: make(map[multicluster.ClusterName]*manifests.Cluster),
+	}, nil
+}
+
+// Get implements multicluster.Provider.
+func (p *ReplayProvider) Get(_ context.Context, clusterName multicluster.ClusterName) (cluster.Cluster, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	cl, ok := p.clusters[clusterName]
+	if !ok {
+		return nil, multicluster.ErrClusterNotFound
+	}
+
+	return cl, nil
+}
+
+// IndexField implements multicluster.Provider.
+func (p *ReplayProvider) IndexField(_ context.Context, _ client.Object, _ string, _ client.IndexerFunc) error {
+	return errors.New("field indexes are not supported for replays")
+}
+
+// Start implements multicluster.ProviderRunnable.
+// It engages the clusters and replays the events. It blocks until the
+// context is done.
+func (p *ReplayProvider) Start(ctx context.Context, aware multicluster.Aware) error {
+	for _, event := range p.events {
+		clusterName := multicluster.ClusterName(event.Cluster)
+
+		p.lock.Lock()
+		_, ok := p.clusters[clusterName]
+		p.lock.Unlock()
+
+		if ok {
+			continue
+		}
+
+		cl, err := manifests.NewCluster(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create cluster %s: %w", clusterName, err)
+		}
+
+		p.lock.Lock()
+		p.clusters[clusterName] = cl
+		p.lock.Unlock()
+
+		if err := aware.Engage(ctx, clusterName, cl); err != nil {
+			return fmt.Errorf("failed to engage cluster %s: %w", clusterName, err)
+		}
+	}
+
+	if err := p.replay(ctx); err != nil {
+		return err
+	}
+
+	p.logger.Info("replay finished", "events", len(p.events))
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// replay applies the events to the clusters at the recorded times.
+func (p *ReplayProvider) replay(ctx context.Context) error {
+	if len(p.events) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	first := p.events[0].Time
+
+	for i, event := range p.events {
+		offset := time.Duration(float64(event.Time.Sub(first)) / p.opts.Speed)
+
+		if wait := time.Until(start.Add(offset)); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(wait):
+			}
+		}
+
+		p.logger.V(2).Info("replaying event", "index", i, "time", event.Time, "cluster", event.Cluster,
+			"kind", event.Kind, "namespace", event.Namespace, "name", event.Name, "deleted", event.Object == nil)
+
+		if err := p.apply(ctx, event); err != nil {
+			p.logger.Error(err, "failed to replay event", "index", i)
+		}
+	}
+
+	return nil
+}
+
+func (p *ReplayProvider) apply(ctx context.Context, event Event) error {
+	p.lock.Lock()
+	cl := p.clusters[multicluster.ClusterName(event.Cluster)]
+	p.lock.Unlock()
+
+	if event.Object == nil {
+		return cl.Delete(ctx, event.GroupVersionKind(), event.Namespace, event.Name)
+	}
+
+	return cl.Apply(ctx, event.Object)
+}
@@ -0,0 +1,92 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+type engager struct {
+	lock     sync.Mutex
+	clusters map[multicluster.ClusterName]cluster.Cluster
+}
+
+func (e *engager) Engage(_ context.Context, name multicluster.ClusterName, cl cluster.Cluster) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.clusters[name] = cl
+
+	return nil
+}
+
+func TestReplayProvider(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(buf)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time {
+		// an hour between events, replayed at high speed below
+		now = now.Add(time.Hour)
+		return now
+	}
+
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", newSecret("a", "1")))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "b", newSecret("b", "1")))
+	require.NoError(t, recorder.Record("cluster1", secretGVK, "default", "a", nil))
+
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	provider, err := NewReplayProvider(ReplayOptions{Path: path, Speed: 3600 * 100})
+	require.NoError(t, err)
+
+	aware := &engager{clusters: map[multicluster.ClusterName]cluster.Cluster{}}
+
+	go func() {
+		_ = provider.Start(t.Context(), aware)
+	}()
+
+	list := func() []string {
+		cl, err := provider.Get(t.Context(), "cluster1")
+		if err != nil {
+			return nil
+		}
+
+		secrets := &unstructured.UnstructuredList{}
+		secrets.SetAPIVersion("v1")
+		secrets.SetKind("SecretList")
+
+		if err := cl.GetCache().List(t.Context(), secrets, client.InNamespace("default")); err != nil {
+			return nil
+		}
+
+		names := []string{}
+		for _, secret := range secrets.Items {
+			names = append(names, secret.GetName())
+		}
+
+		return names
+	}
+
+	require.Eventually(t, func() bool {
+		names := list()
+		return len(names) == 1 && names[0] == "b"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	aware.lock.Lock()
+	require.Contains(t, aware.clusters, multicluster.ClusterName("cluster1"))
+	aware.lock.Unlock()
+}
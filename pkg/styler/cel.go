@@ -2,16 +2,10 @@ package styler
 
 import (
 	"context"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/pem"
 	"fmt"
-	"reflect"
 
 	"github.com/google/cel-go/cel"
-	"github.com/google/cel-go/common/types"
-	"github.com/google/cel-go/common/types/ref"
-	"github.com/google/cel-go/ext"
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -25,87 +19,83 @@ type CELEnv struct {
 // NewCELEnv creates and initializes a new CELEnv with the necessary cel
 // environment and custom functions for evaluating expressions.
 func NewCELEnv() (*CELEnv, error) {
-	celEnv := &CELEnv{}
-
-	envOpts := []cel.EnvOption{
-		cel.Variable("resources", cel.DynType),
-
-		ext.Bindings(),
-		ext.Encoders(),
-		ext.Lists(),
-		ext.Math(),
-		ext.Protos(),
-		ext.Sets(),
-		ext.Strings(),
-
-		ext.NativeTypes(reflect.TypeFor[*x509.Certificate]()),
-		cel.Function("parseCert",
-			cel.Overload(
-				"parseCert_dyn",
-				[]*cel.Type{cel.DynType},
-				cel.DynType,
-				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
-					var b []byte
-
-					switch v := args[0].Value().(type) {
-					case string:
-						var err error
-
-						b, err = base64.StdEncoding.DecodeString(v)
-						if err != nil {
-							return types.WrapErr(fmt.Errorf("base64 decode failed: %w", err))
-						}
-					case []byte:
-						b = v
-					default:
-						return types.WrapErr(fmt.Errorf("unsupported type for parseCert: %T", v))
-					}
-
-					if block, _ := pem.Decode(b); block != nil {
-						b = block.Bytes
-					}
-
-					cert, err := x509.ParseCertificate(b)
-					if err != nil {
-						return types.WrapErr(fmt.Errorf("parseCertificate failed: %w", err))
-					}
-
-					return celEnv.Environment.CELTypeAdapter().NativeToValue(cert)
-				}),
-			),
-		),
-	}
-
-	var err error
-
-	celEnv.Environment, err = cel.NewEnv(envOpts...)
+	env, err := mklv1alpha1.NewCELEnv()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+		return nil, err
 	}
 
-	return celEnv, nil
+	return &CELEnv{Environment: env}, nil
 }
 
-func (celEnv *CELEnv) expandLabel(ctx context.Context, label string, resources []unstructured.Unstructured) (string, error) {
-	ast, issues := celEnv.Environment.Compile(label)
+func (celEnv *CELEnv) program(expression string) (cel.Program, error) {
+	ast, issues := celEnv.Environment.Compile(expression)
 	if issues.Err() != nil {
-		return "", fmt.Errorf("failed to compile CEL expression %s: %w", label, issues.Err())
+		return nil, fmt.Errorf("failed to compile CEL expression %s: %w", expression, issues.Err())
 	}
 
 	prg, err := celEnv.Environment.Program(ast)
 	if err != nil {
-		return "", fmt.Errorf("failed to create CEL program for expression %s: %w", label, err)
+		return nil, fmt.Errorf("failed to create CEL program for expression %s: %w", expression, err)
 	}
 
+	return prg, nil
+}
+
+func convertResources(resources []unstructured.Unstructured) []map[string]any {
 	convertedResources := make([]map[string]any, len(resources))
 	for i, resource := range resources {
 		convertedResources[i] = resource.UnstructuredContent()
 	}
 
-	val, _, err := prg.ContextEval(ctx, map[string]any{"resources": convertedResources})
+	return convertedResources
+}
+
+func (celEnv *CELEnv) expandLabel(ctx context.Context, label string, resources []unstructured.Unstructured) (string, error) {
+	prg, err := celEnv.program(label)
+	if err != nil {
+		return "", err
+	}
+
+	val, _, err := prg.ContextEval(ctx, map[string]any{"resources": convertResources(resources)})
 	if err != nil {
 		return "", fmt.Errorf("failed to evaluate CEL expression %s: %w", label, err)
 	}
 
-	return val.Value().(string), nil
+	expanded, ok := val.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("CEL expression %s must return a string, got %T", label, val.Value())
+	}
+
+	return expanded, nil
+}
+
+// evalStatus evaluates the health expression once for each resource
+// and returns the resulting status for each resource in the same order.
+func (celEnv *CELEnv) evalStatus(ctx context.Context, expression string, resources []unstructured.Unstructured) ([]mklv1alpha1.ResourceStatus, error) {
+	prg, err := celEnv.program(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	convertedResources := convertResources(resources)
+	statuses := make([]mklv1alpha1.ResourceStatus, len(resources))
+
+	for i, resource := range convertedResources {
+		val, _, err := prg.ContextEval(ctx, map[string]any{
+			"resource":  resource,
+			"resources": convertedResources,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate CEL expression %s: %w", expression, err)
+		}
+
+		status, ok := val.Value().(string)
+		if !ok {
+			return nil, fmt.Errorf("CEL expression %s must return a string, got %T", expression, val.Value())
+		}
+
+		statuses[i] = mklv1alpha1.ResourceStatus(status)
+	}
+
+	return statuses, nil
 }
@@ -0,0 +1,30 @@
+package styler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExpandLabel(t *testing.T) {
+	t.Parallel()
+
+	celEnv, err := NewCELEnv()
+	require.NoError(t, err)
+
+	resource := unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"replicas": int64(3)},
+	}}
+	resource.SetName("deployment")
+	resources := []unstructured.Unstructured{resource}
+
+	label, err := celEnv.expandLabel(t.Context(), `resources[0].metadata.name`, resources)
+	require.NoError(t, err)
+	require.Equal(t, "deployment", label)
+
+	// Dynamically typed expressions pass validation but may not
+	// return a string.
+	_, err = celEnv.expandLabel(t.Context(), `resources[0].spec.replicas`, resources)
+	require.ErrorContains(t, err, "must return a string, got int64")
+}
@@ -0,0 +1,138 @@
+package styler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/mermaid"
+)
+
+// edgePrefix is prepended to edge names to distinguish them from node
+// names in the watches. Node IDs in mermaid cannot contain colons.
+const edgePrefix = "edge:"
+
+type edgeStyling struct {
+	style string
+	// label is nil if the edge has no label
+	label *string
+}
+
+// edgeNode returns the edge as a node to share the watches and status
+// logic with nodes.
+func edgeNode(edge mklv1alpha1.Edge) mklv1alpha1.Node {
+	return mklv1alpha1.Node{
+		Selector: edge.Selector,
+		Health:   edge.Health,
+		Label:    edge.Label,
+	}
+}
+
+func (s *Styler) updateEdgeStyling(ctx context.Context, edgeName string, node mklv1alpha1.Node) error {
+	logger := s.Logger.WithValues("edgeName", edgeName)
+	logger.V(2).Info("updating styling for edge")
+
+	resources := s.resources.get(edgePrefix + edgeName)
+
+	status, err := resourceStatus(ctx, s.cel, s.style, node, resources)
+	if err != nil {
+		logger.Error(err, "failed to determine status", "expression", node.Health.Expression)
+	}
+
+	logger.V(2).Info("updated status", "status", status, "resourceCount", len(resources))
+
+	style, ok := s.style.EdgeStatus[status]
+	if !ok {
+		style = status.DefaultEdgeStyle()
+	}
+
+	if style == "" {
+		logger.Info("no edge style for status, using the style of unknown", "status", status)
+
+		style, ok = s.style.EdgeStatus[mklv1alpha1.ResourceUnknown]
+		if !ok {
+			style = mklv1alpha1.ResourceUnknown.DefaultEdgeStyle()
+		}
+	}
+
+	newStyling := edgeStyling{style: style}
+
+	if node.Label != "" {
+		label, err := s.cel.expandLabel(ctx, node.Label, resources)
+		if err != nil {
+			logger.Error(err, "failed to expand label, skipping label update", "label", node.Label)
+		} else {
+			logger.V(2).Info("expanded label", "label", node.Label, "expanded", label)
+			newStyling.label = &label
+		}
+	}
+
+	s.styleLock.Lock()
+	if _, ok := s.edges[edgeName]; !ok {
+		// edge was removed from the config while it was reconciled
+		s.styleLock.Unlock()
+		return nil
+	}
+
+	oldStyling, ok := s.edgeStyles[edgeName]
+	changed := !ok || oldStyling.style != newStyling.style ||
+		(oldStyling.label == nil) != (newStyling.label == nil) ||
+		(oldStyling.label != nil && *oldStyling.label != *newStyling.label)
+	s.edgeStyles[edgeName] = newStyling
+	s.styleLock.Unlock()
+
+	if changed {
+		logger.V(2).Info("styling changed")
+		s.notifyChange()
+	}
+
+	return nil
+}
+
+// edgeStyling replaces the labels of the configured edges in the diagram
+// and returns the linkStyle directives for them.
+func (s *Styler) edgeStyling(diagram []byte) ([]byte, string) {
+	links := mermaid.Links(diagram)
+	labels := map[int]string{}
+
+	var ret strings.Builder
+
+	s.styleLock.RLock()
+	defer s.styleLock.RUnlock()
+
+	for _, edgeName := range slices.Sorted(maps.Keys(s.edgeStyles)) {
+		edge, ok := s.edges[edgeName]
+		if !ok {
+			// edge was removed from the config
+			continue
+		}
+
+		styling := s.edgeStyles[edgeName]
+		found := false
+
+		for _, link := range links {
+			if link.From != edge.From || link.To != edge.To {
+				continue
+			}
+
+			found = true
+
+			if styling.style != "" {
+				fmt.Fprintf(&ret, "linkStyle %d %s\n", link.Index, styling.style)
+			}
+
+			if styling.label != nil {
+				labels[link.Index] = *styling.label
+			}
+		}
+
+		if !found {
+			s.Logger.V(2).Info("no link found in diagram for edge", "edgeName", edgeName, "from", edge.From, "to", edge.To)
+		}
+	}
+
+	return mermaid.SetLinkLabels(diagram, links, labels), ret.String()
+}
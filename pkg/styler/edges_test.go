@@ -0,0 +1,39 @@
+package styler
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStyleDiagramEdges(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	edge := mklv1alpha1.Edge{
+		From:  "cluster1secret",
+		To:    "cluster2secret",
+		Label: `string(size(resources)) + " copied"`,
+	}
+	s.edges = map[string]mklv1alpha1.Edge{"copy": edge}
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("our-first-secret")
+	s.resources.replace(edgePrefix+"copy", "cluster", resource)
+
+	require.NoError(t, s.updateStyling(t.Context(), edgePrefix+"copy", edgeNode(edge)))
+	require.Len(t, s.Changes(), 1)
+
+	styled, err := s.StyleDiagram([]byte("flowchart TD\n  a --> b\n  cluster1secret -- copy --> cluster2secret\n"))
+	require.NoError(t, err)
+	require.Equal(t,
+		"flowchart TD\n  a --> b\n  cluster1secret -->|\"1 copied\"| cluster2secret\n\n"+
+			"linkStyle 1 "+mklv1alpha1.ResourceHealthy.DefaultEdgeStyle()+"\n",
+		string(styled),
+	)
+}
@@ -0,0 +1,50 @@
+package styler
+
+import (
+	"context"
+	"errors"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Evaluation is the status and label of a node for a set of resources.
+type Evaluation struct {
+	// Status is the status of the node.
+	Status mklv1alpha1.ResourceStatus
+	// Label is the expanded label, nil if the node has no label.
+	Label *string
+}
+
+// Evaluate determines the status and label of the node for the
+// resources the same way the Styler does for the resources matched in
+// the clusters.
+// Errors of the health expression and the label are joined, the
+// evaluation contains the values the Styler would use in that case.
+func Evaluate(ctx context.Context, celEnv *CELEnv, style mklv1alpha1.Style, node mklv1alpha1.Node, resources []unstructured.Unstructured) (Evaluation, error) {
+	var errs []error
+
+	status, err := resourceStatus(ctx, celEnv, style, node, resources)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	evaluation := Evaluation{Status: status}
+
+	if node.Label != "" {
+		label, err := celEnv.expandLabel(ctx, node.Label, resources)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			evaluation.Label = &label
+		}
+	}
+
+	return evaluation, errors.Join(errs...)
+}
+
+// EvaluateEdge determines the status and label of the edge for the
+// resources like Evaluate.
+func EvaluateEdge(ctx context.Context, celEnv *CELEnv, style mklv1alpha1.Style, edge mklv1alpha1.Edge, resources []unstructured.Unstructured) (Evaluation, error) {
+	return Evaluate(ctx, celEnv, style, edgeNode(edge), resources)
+}
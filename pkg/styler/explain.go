@@ -0,0 +1,140 @@
+package styler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Explanation describes how the status and label of a node were
+// determined.
+type Explanation struct {
+	// Node is the name of the node.
+	Node string
+	// Status is the status of the node.
+	Status mklv1alpha1.ResourceStatus
+	// Aggregation is the policy used to combine the statuses of the
+	// resources.
+	Aggregation mklv1alpha1.AggregationPolicy
+	// Label is the expanded label of the node, if the node has a label.
+	Label string
+	// Error is set if the status or label could not be determined.
+	Error string
+	// Resources are the resources matched by the node.
+	Resources []ExplainedResource
+}
+
+// ExplainedResource is a resource matched by a node and its status.
+type ExplainedResource struct {
+	Cluster   string
+	Kind      string
+	Namespace string
+	Name      string
+	Status    mklv1alpha1.ResourceStatus
+	// Reason describes why the resource has the status.
+	Reason string
+}
+
+// Explain explains the status and label of the node based on the
+// currently tracked resources.
+func (s *Styler) Explain(ctx context.Context, nodeName string) (Explanation, error) {
+	s.styleLock.RLock()
+	node, ok := s.nodes[nodeName]
+	s.styleLock.RUnlock()
+
+	if !ok {
+		return Explanation{}, fmt.Errorf("node %q is not configured", nodeName)
+	}
+
+	tracked := s.resources.tracked(nodeName)
+	resources := make([]unstructured.Unstructured, len(tracked))
+
+	explanation := Explanation{
+		Node:        nodeName,
+		Aggregation: node.Health.Aggregation.Policy,
+		Resources:   make([]ExplainedResource, len(tracked)),
+	}
+	if explanation.Aggregation == "" {
+		explanation.Aggregation = mklv1alpha1.AggregationWorst
+	}
+
+	for i, t := range tracked {
+		resources[i] = t.resource
+		explanation.Resources[i] = ExplainedResource{
+			Cluster:   string(t.cluster),
+			Kind:      t.resource.GetKind(),
+			Namespace: t.resource.GetNamespace(),
+			Name:      t.resource.GetName(),
+		}
+	}
+
+	var errs []string
+
+	if node.Health.Expression != "" {
+		statuses, err := s.cel.evalStatus(ctx, node.Health.Expression, resources)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		for i, status := range statuses {
+			explanation.Resources[i].Status = status
+			explanation.Resources[i].Reason = "returned by the health expression"
+		}
+	} else {
+		for i, resource := range resources {
+			explanation.Resources[i].Status, explanation.Resources[i].Reason = builtinStatusReason(node.Health, resource)
+		}
+	}
+
+	status, err := resourceStatus(ctx, s.cel, s.style, node, resources)
+	if err != nil && node.Health.Expression == "" {
+		errs = append(errs, err.Error())
+	}
+
+	explanation.Status = status
+
+	if node.Label != "" {
+		label, err := s.cel.expandLabel(ctx, node.Label, resources)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		explanation.Label = label
+	}
+
+	explanation.Error = strings.Join(errs, "; ")
+
+	return explanation, nil
+}
+
+// String formats the explanation for humans.
+func (e Explanation) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Node:   %s\n", e.Node)
+	fmt.Fprintf(&sb, "Status: %s (aggregation %s)\n", e.Status, e.Aggregation)
+
+	if e.Label != "" {
+		fmt.Fprintf(&sb, "Label:  %s\n", e.Label)
+	}
+
+	if e.Error != "" {
+		fmt.Fprintf(&sb, "Error:  %s\n", e.Error)
+	}
+
+	fmt.Fprintf(&sb, "Resources: %d\n", len(e.Resources))
+
+	for _, r := range e.Resources {
+		name := r.Name
+		if r.Namespace != "" {
+			name = r.Namespace + "/" + name
+		}
+
+		fmt.Fprintf(&sb, "  %s %s %s: %s (%s)\n", r.Cluster, r.Kind, name, r.Status, r.Reason)
+	}
+
+	return sb.String()
+}
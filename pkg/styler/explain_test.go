@@ -0,0 +1,58 @@
+package styler
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	s.nodes = map[string]mklv1alpha1.Node{
+		"pods": {
+			Health: mklv1alpha1.Health{ConditionType: "Ready"},
+			Label:  `string(size(resources)) + " pods"`,
+		},
+	}
+
+	_, err = s.Explain(t.Context(), "unknown")
+	require.Error(t, err)
+
+	pod := func(name, ready string) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": ready},
+				},
+			},
+		}}
+		u.SetKind("Pod")
+		u.SetNamespace("default")
+		u.SetName(name)
+
+		return u
+	}
+
+	s.resources.replace("pods", "cluster1", pod("a", "True"))
+	s.resources.replace("pods", "cluster2", pod("b", "False"))
+
+	explanation, err := s.Explain(t.Context(), "pods")
+	require.NoError(t, err)
+	require.Equal(t, Explanation{
+		Node:        "pods",
+		Status:      mklv1alpha1.ResourcePending,
+		Aggregation: mklv1alpha1.AggregationWorst,
+		Label:       "2 pods",
+		Resources: []ExplainedResource{
+			{Cluster: "cluster1", Kind: "Pod", Namespace: "default", Name: "a", Status: mklv1alpha1.ResourceHealthy, Reason: "condition Ready is True"},
+			{Cluster: "cluster2", Kind: "Pod", Namespace: "default", Name: "b", Status: mklv1alpha1.ResourcePending, Reason: "condition Ready is False"},
+		},
+	}, explanation)
+}
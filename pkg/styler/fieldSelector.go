@@ -0,0 +1,70 @@
+package styler
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// fieldSelectorMatcher returns a function matching objects against the
+// field selector.
+// Unlike the API server, which only supports a few fields per
+// resource, any field of the object can be selected. Missing fields
+// are treated as empty strings.
+// The selector is not passed to the API server as the informers are
+// shared between all nodes watching the same kind in a cluster and a
+// per-node informer would multiply the watches.
+func fieldSelectorMatcher(fieldSelector string) (func(obj client.Object) bool, error) {
+	selector, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field selector %q: %w", fieldSelector, err)
+	}
+
+	requirements := selector.Requirements()
+
+	return func(obj client.Object) bool {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return false
+		}
+
+		set := fields.Set{}
+
+		for _, req := range requirements {
+			val, found, err := unstructured.NestedFieldNoCopy(u.Object, strings.Split(req.Field, ".")...)
+			if err != nil || !found || val == nil {
+				set[req.Field] = ""
+				continue
+			}
+
+			set[req.Field] = fmt.Sprint(val)
+		}
+
+		return selector.Matches(set)
+	}, nil
+}
+
+// transitionPredicate passes events for objects that match or matched
+// before an update. This allows the reconciler to stop tracking objects
+// that no longer match, e.g. Pods that changed their phase.
+func transitionPredicate(matches func(obj client.Object) bool) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return matches(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return matches(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return matches(e.ObjectOld) || matches(e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return matches(e.Object)
+		},
+	}
+}
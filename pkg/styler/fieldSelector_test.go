@@ -0,0 +1,43 @@
+package styler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestFieldSelectorMatcher(t *testing.T) {
+	t.Parallel()
+
+	pod := func(nodeName, phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"metadata": map[string]any{"name": "pod"},
+			"spec":     map[string]any{"nodeName": nodeName},
+			"status":   map[string]any{"phase": phase},
+		}}
+	}
+
+	matches, err := fieldSelectorMatcher("spec.nodeName=node1,status.phase!=Failed")
+	require.NoError(t, err)
+
+	require.True(t, matches(pod("node1", "Running")))
+	require.False(t, matches(pod("node1", "Failed")))
+	require.False(t, matches(pod("node2", "Running")))
+
+	matches, err = fieldSelectorMatcher("status.reason=")
+	require.NoError(t, err)
+	require.True(t, matches(pod("node1", "Running")), "missing fields are empty")
+
+	_, err = fieldSelectorMatcher("spec.nodeName")
+	require.Error(t, err)
+
+	matches, err = fieldSelectorMatcher("status.phase=Failed")
+	require.NoError(t, err)
+
+	p := transitionPredicate(matches)
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: pod("node1", "Running"), ObjectNew: pod("node1", "Failed")}))
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: pod("node1", "Failed"), ObjectNew: pod("node1", "Running")}))
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: pod("node1", "Pending"), ObjectNew: pod("node1", "Running")}))
+}
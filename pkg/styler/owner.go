@@ -0,0 +1,112 @@
+package styler
+
+import (
+	"context"
+	"fmt"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ownerRefs returns the owner references of the object to consider for
+// the owner selector.
+func ownerRefs(owner mklv1alpha1.OwnerReference, obj metav1.Object) []metav1.OwnerReference {
+	if owner.AllOwners {
+		return obj.GetOwnerReferences()
+	}
+
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return []metav1.OwnerReference{*ref}
+	}
+
+	return nil
+}
+
+// ownerRefMatches returns true if the owner reference references the
+// owner.
+func ownerRefMatches(owner mklv1alpha1.OwnerReference, ref metav1.OwnerReference) bool {
+	return ref.APIVersion == owner.GVK.GroupVersion().String() &&
+		ref.Kind == owner.GVK.Kind &&
+		ref.Name == owner.Name
+}
+
+// ownerDepth returns the number of ownership levels to walk.
+func ownerDepth(owner mklv1alpha1.OwnerReference) int {
+	return max(owner.Depth, 1)
+}
+
+// ownerMatches walks the ownership chain of the object up to the
+// configured depth and returns true if the owner is found.
+// Intermediate owners are resolved through the reader, which is
+// expected to be backed by the cluster cache.
+func ownerMatches(ctx context.Context, reader client.Reader, owner mklv1alpha1.OwnerReference, obj *unstructured.Unstructured) (bool, error) {
+	current := []*unstructured.Unstructured{obj}
+	// guard against ownership cycles
+	seen := map[string]bool{}
+
+	for level := 1; level <= ownerDepth(owner); level++ {
+		var next []*unstructured.Unstructured
+
+		for _, child := range current {
+			for _, ref := range ownerRefs(owner, child) {
+				if ownerRefMatches(owner, ref) {
+					return true, nil
+				}
+
+				if level == ownerDepth(owner) || seen[string(ref.UID)] {
+					continue
+				}
+
+				seen[string(ref.UID)] = true
+
+				parent, err := getOwner(ctx, reader, child.GetNamespace(), ref)
+				if err != nil {
+					return false, err
+				}
+
+				if parent != nil {
+					next = append(next, parent)
+				}
+			}
+		}
+
+		current = next
+	}
+
+	return false, nil
+}
+
+// getOwner fetches the owner from the reader. Returns nil if the owner
+// does not exist.
+func getOwner(ctx context.Context, reader client.Reader, namespace string, ref metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner reference api version %q: %w", ref.APIVersion, err)
+	}
+
+	parent := &unstructured.Unstructured{}
+	parent.SetGroupVersionKind(gv.WithKind(ref.Kind))
+
+	// Owners are always in the same namespace as the owned object or
+	// cluster-scoped.
+	namespaces := []string{namespace}
+	if namespace != "" {
+		namespaces = append(namespaces, "")
+	}
+
+	for _, ns := range namespaces {
+		err := reader.Get(ctx, client.ObjectKey{Namespace: ns, Name: ref.Name}, parent)
+		if err == nil {
+			return parent, nil
+		}
+
+		if client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("failed to get owner %s %s/%s: %w", ref.Kind, ns, ref.Name, err)
+		}
+	}
+
+	return nil, nil //nolint:nilnil // a missing owner is not an error
+}
@@ -0,0 +1,75 @@
+package styler
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOwnerMatches(t *testing.T) {
+	t.Parallel()
+
+	object := func(apiVersion, kind, name string, controller bool, owners ...*unstructured.Unstructured) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace("default")
+		u.SetUID(types.UID(kind + "/" + name))
+
+		refs := make([]metav1.OwnerReference, len(owners))
+		for i, owner := range owners {
+			refs[i] = metav1.OwnerReference{
+				APIVersion: owner.GetAPIVersion(),
+				Kind:       owner.GetKind(),
+				Name:       owner.GetName(),
+				UID:        owner.GetUID(),
+				Controller: ptr.To(controller),
+			}
+		}
+		u.SetOwnerReferences(refs)
+
+		return u
+	}
+
+	deployment := object("apps/v1", "Deployment", "frontend", true)
+	replicaSet := object("apps/v1", "ReplicaSet", "frontend-abc", true, deployment)
+	pod := object("v1", "Pod", "frontend-abc-xyz", true, replicaSet)
+	configMap := object("v1", "ConfigMap", "frontend-config", false, deployment)
+
+	reader := fake.NewClientBuilder().WithObjects(deployment, replicaSet, pod, configMap).Build()
+
+	owner := mklv1alpha1.OwnerReference{
+		GVK:  schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Name: "frontend",
+	}
+
+	ok, err := ownerMatches(t.Context(), reader, owner, replicaSet)
+	require.NoError(t, err)
+	require.True(t, ok, "direct owner")
+
+	ok, err = ownerMatches(t.Context(), reader, owner, pod)
+	require.NoError(t, err)
+	require.False(t, ok, "deployment is not the direct owner of the pod")
+
+	owner.Depth = 2
+	ok, err = ownerMatches(t.Context(), reader, owner, pod)
+	require.NoError(t, err)
+	require.True(t, ok, "deployment owns the pod through the replicaset")
+
+	ok, err = ownerMatches(t.Context(), reader, owner, configMap)
+	require.NoError(t, err)
+	require.False(t, ok, "only controller owners are considered by default")
+
+	owner.AllOwners = true
+	ok, err = ownerMatches(t.Context(), reader, owner, configMap)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
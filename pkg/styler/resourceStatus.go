@@ -1,44 +1,115 @@
 package styler
 
 import (
+	"context"
+	"fmt"
+
 	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-func resourceStatus(node mklv1alpha1.Node, resources []unstructured.Unstructured) mklv1alpha1.ResourceStatus {
+func resourceStatus(ctx context.Context, celEnv *CELEnv, style mklv1alpha1.Style, node mklv1alpha1.Node, resources []unstructured.Unstructured) (mklv1alpha1.ResourceStatus, error) {
 	if len(resources) == 0 {
-		return mklv1alpha1.ResourceAbsent
+		return mklv1alpha1.ResourceAbsent, nil
 	}
 
-	if node.Health.WhenPresent && len(resources) > 0 {
-		return mklv1alpha1.ResourceHealthy
+	if node.Health.Expression != "" {
+		statuses, err := celEnv.evalStatus(ctx, node.Health.Expression, resources)
+		if err != nil {
+			return mklv1alpha1.ResourceUnknown, err
+		}
+
+		return aggregateStatus(style.Precedence, node.Health.Aggregation, statuses)
 	}
 
-	if allOk(resources, node.Health.ConditionType) {
-		return mklv1alpha1.ResourceHealthy
+	statuses := make([]mklv1alpha1.ResourceStatus, len(resources))
+	for i, resource := range resources {
+		statuses[i] = builtinStatus(node.Health, resource)
 	}
 
-	return mklv1alpha1.ResourcePending
+	return aggregateStatus(style.Precedence, node.Health.Aggregation, statuses)
 }
 
-func allOk(items []unstructured.Unstructured, healthType string) bool {
-	for _, item := range items {
-		status, found, err := unstructured.NestedSlice(item.Object, "status", "conditions")
-		if err != nil || !found {
+// aggregateStatus combines the statuses of multiple resources according
+// to the aggregation policy.
+func aggregateStatus(precedence []mklv1alpha1.ResourceStatus, aggregation mklv1alpha1.Aggregation, statuses []mklv1alpha1.ResourceStatus) (mklv1alpha1.ResourceStatus, error) {
+	healthy := 0
+	unhealthy := make([]mklv1alpha1.ResourceStatus, 0, len(statuses))
+
+	for _, status := range statuses {
+		if status == mklv1alpha1.ResourceHealthy {
+			healthy++
 			continue
 		}
 
-		if !statusOk(status, healthType) {
-			return false
-		}
+		unhealthy = append(unhealthy, status)
+	}
+
+	var ok bool
+
+	switch aggregation.Policy {
+	case "", mklv1alpha1.AggregationWorst:
+		return mklv1alpha1.MostSignificant(precedence, statuses...), nil
+	case mklv1alpha1.AggregationBest:
+		return mklv1alpha1.LeastSignificant(precedence, statuses...), nil
+	case mklv1alpha1.AggregationAll:
+		ok = len(unhealthy) == 0
+	case mklv1alpha1.AggregationAny:
+		ok = healthy > 0
+	case mklv1alpha1.AggregationAtLeast:
+		ok = healthy >= aggregation.Count
+	case mklv1alpha1.AggregationPercentage:
+		ok = len(statuses) > 0 && healthy*100 >= aggregation.Percentage*len(statuses)
+	default:
+		return mklv1alpha1.ResourceUnknown, fmt.Errorf("unknown aggregation policy %q", aggregation.Policy)
+	}
+
+	if ok {
+		return mklv1alpha1.ResourceHealthy, nil
+	}
+
+	if len(unhealthy) == 0 {
+		// e.g. fewer resources than required by the atLeast policy
+		return mklv1alpha1.ResourcePending, nil
+	}
+
+	return mklv1alpha1.MostSignificant(precedence, unhealthy...), nil
+}
+
+// builtinStatus determines the status of a single resource without
+// a health expression.
+func builtinStatus(health mklv1alpha1.Health, resource unstructured.Unstructured) mklv1alpha1.ResourceStatus {
+	status, _ := builtinStatusReason(health, resource)
+	return status
+}
+
+// builtinStatusReason determines the status of a single resource
+// without a health expression and describes why the resource has the
+// status.
+func builtinStatusReason(health mklv1alpha1.Health, resource unstructured.Unstructured) (mklv1alpha1.ResourceStatus, string) {
+	if resource.GetDeletionTimestamp() != nil {
+		return mklv1alpha1.ResourceTerminating, "resource is being deleted"
+	}
+
+	if phase, _, _ := unstructured.NestedString(resource.Object, "status", "phase"); phase == "Failed" {
+		return mklv1alpha1.ResourceFailed, "phase is Failed"
 	}
 
-	return true
+	if health.WhenPresent {
+		return mklv1alpha1.ResourceHealthy, "resource is present"
+	}
+
+	conditions, found, err := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	if err != nil || !found {
+		return mklv1alpha1.ResourceHealthy, "resource is present and has no conditions"
+	}
+
+	return conditionStatus(conditions, health.ConditionType)
 }
 
-func statusOk(status []any, healthType string) bool {
-	for _, cond := range status {
+func conditionStatus(conditions []any, healthType string) (mklv1alpha1.ResourceStatus, string) {
+	for _, cond := range conditions {
 		condMap, ok := cond.(map[string]any)
 		if !ok {
 			continue
@@ -48,8 +119,17 @@ func statusOk(status []any, healthType string) bool {
 			continue
 		}
 
-		return condMap["status"] == string(metav1.ConditionTrue)
+		reason := fmt.Sprintf("condition %s is %v", healthType, condMap["status"])
+
+		switch condMap["status"] {
+		case string(metav1.ConditionTrue):
+			return mklv1alpha1.ResourceHealthy, reason
+		case string(metav1.ConditionUnknown):
+			return mklv1alpha1.ResourceUnknown, reason
+		default:
+			return mklv1alpha1.ResourcePending, reason
+		}
 	}
 	// default to ok if the condition type is not found
-	return true
+	return mklv1alpha1.ResourceHealthy, fmt.Sprintf("condition %q not found", healthType)
 }
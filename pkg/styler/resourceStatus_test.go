@@ -0,0 +1,207 @@
+package styler
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func TestResourceStatusExpression(t *testing.T) {
+	t.Parallel()
+
+	celEnv, err := NewCELEnv()
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{
+		Health: mklv1alpha1.Health{
+			Expression: `resource.status.phase == "Running" ? "healthy" : "pending"`,
+		},
+	}
+
+	pod := func(phase string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{"phase": phase},
+		}}
+	}
+
+	status, err := resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, nil)
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourceAbsent, status)
+
+	status, err = resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, []unstructured.Unstructured{pod("Running"), pod("Running")})
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourceHealthy, status)
+
+	status, err = resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, []unstructured.Unstructured{pod("Running"), pod("Pending")})
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourcePending, status)
+
+	node.Health.Expression = `size(resources) > 1 ? "healthy" : "pending"`
+	status, err = resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, []unstructured.Unstructured{pod("Running")})
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourcePending, status)
+
+	node.Health.Expression = `resource.status.phase == "CrashLoopBackOff" ? "crashloop" : "healthy"`
+	status, err = resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, []unstructured.Unstructured{pod("Running"), pod("CrashLoopBackOff")})
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourceStatus("crashloop"), status)
+
+	node.Health.Expression = `1`
+	status, err = resourceStatus(t.Context(), celEnv, mklv1alpha1.Style{}, node, []unstructured.Unstructured{pod("Running")})
+	require.Error(t, err)
+	require.Equal(t, mklv1alpha1.ResourceUnknown, status)
+}
+
+func TestResourceStatusBuiltin(t *testing.T) {
+	t.Parallel()
+
+	celEnv, err := NewCELEnv()
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{
+		Health: mklv1alpha1.Health{
+			ConditionType: "Ready",
+		},
+	}
+
+	resource := func(phase string, ready metav1.ConditionStatus, deleting bool) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{
+				"phase": phase,
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": string(ready)},
+				},
+			},
+		}}
+		if deleting {
+			u.SetDeletionTimestamp(ptr.To(metav1.Now()))
+		}
+		return u
+	}
+
+	cases := map[string]struct {
+		style     mklv1alpha1.Style
+		resources []unstructured.Unstructured
+		expected  mklv1alpha1.ResourceStatus
+	}{
+		"healthy": {
+			resources: []unstructured.Unstructured{resource("Running", metav1.ConditionTrue, false)},
+			expected:  mklv1alpha1.ResourceHealthy,
+		},
+		"unknown condition": {
+			resources: []unstructured.Unstructured{resource("Running", metav1.ConditionUnknown, false)},
+			expected:  mklv1alpha1.ResourceUnknown,
+		},
+		"failed beats pending and terminating": {
+			resources: []unstructured.Unstructured{
+				resource("Running", metav1.ConditionFalse, false),
+				resource("Failed", metav1.ConditionFalse, false),
+				resource("Running", metav1.ConditionTrue, true),
+			},
+			expected: mklv1alpha1.ResourceFailed,
+		},
+		"custom precedence": {
+			style: mklv1alpha1.Style{
+				Precedence: []mklv1alpha1.ResourceStatus{
+					mklv1alpha1.ResourceTerminating,
+					mklv1alpha1.ResourceFailed,
+				},
+			},
+			resources: []unstructured.Unstructured{
+				resource("Failed", metav1.ConditionFalse, false),
+				resource("Running", metav1.ConditionTrue, true),
+			},
+			expected: mklv1alpha1.ResourceTerminating,
+		},
+		"partial precedence": {
+			style: mklv1alpha1.Style{
+				Precedence: []mklv1alpha1.ResourceStatus{
+					mklv1alpha1.ResourceFailed,
+					mklv1alpha1.ResourceDegraded,
+				},
+			},
+			resources: []unstructured.Unstructured{
+				resource("Running", metav1.ConditionTrue, false),
+				resource("Failed", metav1.ConditionFalse, false),
+			},
+			expected: mklv1alpha1.ResourceFailed,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := resourceStatus(t.Context(), celEnv, tc.style, node, tc.resources)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	t.Parallel()
+
+	statuses := []mklv1alpha1.ResourceStatus{
+		mklv1alpha1.ResourceHealthy,
+		mklv1alpha1.ResourceHealthy,
+		mklv1alpha1.ResourceHealthy,
+		mklv1alpha1.ResourcePending,
+		mklv1alpha1.ResourceFailed,
+	}
+
+	cases := map[string]struct {
+		aggregation mklv1alpha1.Aggregation
+		expected    mklv1alpha1.ResourceStatus
+	}{
+		"default": {
+			expected: mklv1alpha1.ResourceFailed,
+		},
+		"best": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationBest},
+			expected:    mklv1alpha1.ResourceHealthy,
+		},
+		"all": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationAll},
+			expected:    mklv1alpha1.ResourceFailed,
+		},
+		"any": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationAny},
+			expected:    mklv1alpha1.ResourceHealthy,
+		},
+		"at least fulfilled": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationAtLeast, Count: 3},
+			expected:    mklv1alpha1.ResourceHealthy,
+		},
+		"at least not fulfilled": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationAtLeast, Count: 4},
+			expected:    mklv1alpha1.ResourceFailed,
+		},
+		"percentage fulfilled": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationPercentage, Percentage: 60},
+			expected:    mklv1alpha1.ResourceHealthy,
+		},
+		"percentage not fulfilled": {
+			aggregation: mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationPercentage, Percentage: 61},
+			expected:    mklv1alpha1.ResourceFailed,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := aggregateStatus(nil, tc.aggregation, statuses)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, status)
+		})
+	}
+
+	status, err := aggregateStatus(nil, mklv1alpha1.Aggregation{Policy: mklv1alpha1.AggregationAtLeast, Count: 2}, []mklv1alpha1.ResourceStatus{mklv1alpha1.ResourceHealthy})
+	require.NoError(t, err)
+	require.Equal(t, mklv1alpha1.ResourcePending, status)
+}
@@ -1,20 +1,32 @@
 package styler
 
 import (
+	"maps"
 	"slices"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
 )
 
+// trackedResource is a resource and the cluster it was found in.
+type trackedResource struct {
+	cluster  multicluster.ClusterName
+	resource unstructured.Unstructured
+}
+
+func (t trackedResource) is(clusterName multicluster.ClusterName, name, namespace string) bool {
+	return t.cluster == clusterName && t.resource.GetName() == name && t.resource.GetNamespace() == namespace
+}
+
 type resources struct {
 	lock sync.RWMutex
-	res  map[string][]unstructured.Unstructured
+	res  map[string][]trackedResource
 }
 
 func newResources() *resources {
 	return &resources{
-		res: make(map[string][]unstructured.Unstructured),
+		res: make(map[string][]trackedResource),
 	}
 }
 
@@ -22,10 +34,23 @@ func (r *resources) get(nodeName string) []unstructured.Unstructured {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	return r.res[nodeName]
+	ret := make([]unstructured.Unstructured, len(r.res[nodeName]))
+	for i, tracked := range r.res[nodeName] {
+		ret[i] = tracked.resource
+	}
+
+	return ret
 }
 
-func (r *resources) delete(nodeName string, name, namespace string) {
+// tracked returns the resources of the node with their clusters.
+func (r *resources) tracked(nodeName string) []trackedResource {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return slices.Clone(r.res[nodeName])
+}
+
+func (r *resources) delete(nodeName string, clusterName multicluster.ClusterName, name, namespace string) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
@@ -33,20 +58,31 @@ func (r *resources) delete(nodeName string, name, namespace string) {
 		return
 	}
 
-	r.res[nodeName] = slices.DeleteFunc(r.res[nodeName], func(r unstructured.Unstructured) bool {
-		return r.GetName() == name && r.GetNamespace() == namespace
+	r.res[nodeName] = slices.DeleteFunc(r.res[nodeName], func(t trackedResource) bool {
+		return t.is(clusterName, name, namespace)
 	})
 	if len(r.res[nodeName]) == 0 {
 		delete(r.res, nodeName)
 	}
 }
 
-func (r *resources) replace(nodeName string, resource unstructured.Unstructured) {
+func (r *resources) replace(nodeName string, clusterName multicluster.ClusterName, resource unstructured.Unstructured) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.res[nodeName] = slices.DeleteFunc(r.res[nodeName], func(t trackedResource) bool {
+		return t.is(clusterName, resource.GetName(), resource.GetNamespace())
+	})
+	r.res[nodeName] = append(r.res[nodeName], trackedResource{cluster: clusterName, resource: resource})
+}
+
+// retain deletes the resources of all nodes for which keep returns
+// false.
+func (r *resources) retain(keep func(nodeName string) bool) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
-	r.res[nodeName] = slices.DeleteFunc(r.res[nodeName], func(r unstructured.Unstructured) bool {
-		return r.GetName() == resource.GetName() && r.GetNamespace() == resource.GetNamespace()
+	maps.DeleteFunc(r.res, func(nodeName string, _ []trackedResource) bool {
+		return !keep(nodeName)
 	})
-	r.res[nodeName] = append(r.res[nodeName], resource)
 }
@@ -3,18 +3,29 @@ package styler
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
 	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"github.com/ntnn/mermaid-kube-live/pkg/recording"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	mctrl "sigs.k8s.io/multicluster-runtime"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
 )
 
 // Styler generates styles for the nodes based on the resources
 // associated with them.
 type Styler struct {
-	Logger    logr.Logger
+	Logger logr.Logger
+	// Recorder records the observed resources if set.
+	// It must be set before the configuration is applied.
+	Recorder *recording.Recorder
+
 	style     mklv1alpha1.Style
 	watches   *watches
 	cel       *CELEnv
@@ -22,14 +33,46 @@ type Styler struct {
 
 	// cached data for each node, keyed by node name in the config
 	styleLock sync.RWMutex
+	nodes     map[string]mklv1alpha1.Node
 	styles    map[string][]string
+	states    map[string]NodeState
+	// edges and their cached data, keyed by edge name in the config
+	edges      map[string]mklv1alpha1.Edge
+	edgeStyles map[string]edgeStyling
+
+	// changes is notified whenever the styling changes.
+	changes chan struct{}
+}
+
+// NodeState is the current state of a node.
+type NodeState struct {
+	// Status is the status of the node.
+	Status mklv1alpha1.ResourceStatus
+	// Label is the expanded label of the node, empty if the node has
+	// no label or it could not be expanded.
+	Label string
+	// Resources is the number of resources matched by the node.
+	Resources int
+	// Clusters are the sorted names of the clusters the matched
+	// resources are in.
+	Clusters []string
+	// LastTransitionTime is the time the status last changed.
+	LastTransitionTime time.Time
 }
 
 // New creates a new Styler instance.
-func New(mp *multiplexer.Multiplexer) (*Styler, error) {
+// The name distinguishes multiple stylers sharing the multiplexer and
+// may be empty if there is only one.
+func New(mp *multiplexer.Multiplexer, name string) (*Styler, error) {
 	s := &Styler{}
 	s.Logger = mctrl.Log.WithName("styler")
+	if name != "" {
+		s.Logger = s.Logger.WithValues("diagram", name)
+	}
 	s.styles = make(map[string][]string)
+	s.states = make(map[string]NodeState)
+	s.edgeStyles = make(map[string]edgeStyling)
+	s.changes = make(chan struct{}, 1)
 
 	celEnv, err := NewCELEnv()
 	if err != nil {
@@ -45,19 +88,127 @@ func New(mp *multiplexer.Multiplexer) (*Styler, error) {
 		deleteResource:  s.resources.delete,
 		replaceResource: s.resources.replace,
 		updateStyling:   s.updateStyling,
+		record:          s.record,
 	}
 
-	s.watches = newWatches(mp, rOpts)
+	s.watches = newWatches(mp, name+"/", rOpts)
 
 	return s, nil
 }
 
+func (s *Styler) record(clusterName multicluster.ClusterName, gvk schema.GroupVersionKind, namespace, name string, obj *unstructured.Unstructured) {
+	if s.Recorder == nil {
+		return
+	}
+
+	if err := s.Recorder.Record(clusterName.String(), gvk, namespace, name, obj); err != nil {
+		s.Logger.Error(err, "failed to record resource", "cluster", clusterName, "namespace", namespace, "name", name)
+	}
+}
+
 // UpdateConfig updates the Styler's configuration and refreshes the watches.
 func (s *Styler) UpdateConfig(ctx context.Context, config *mklv1alpha1.Config) error {
 	s.style = config.Style
-	if err := s.watches.update(ctx, config.Nodes); err != nil {
+
+	s.styleLock.Lock()
+	s.nodes = config.Nodes
+	s.edges = config.Edges
+
+	// drop the state of removed nodes and edges, e.g. discovered nodes
+	// whose resources disappeared, so they are no longer styled
+	maps.DeleteFunc(s.styles, func(nodeName string, _ []string) bool {
+		_, ok := config.Nodes[nodeName]
+		return !ok
+	})
+	maps.DeleteFunc(s.states, func(nodeName string, _ NodeState) bool {
+		_, ok := config.Nodes[nodeName]
+		return !ok
+	})
+	maps.DeleteFunc(s.edgeStyles, func(edgeName string, _ edgeStyling) bool {
+		_, ok := config.Edges[edgeName]
+		return !ok
+	})
+	s.styleLock.Unlock()
+	// edges may now refer to other links in the diagram
+	s.notifyChange()
+
+	targets := make(map[string]mklv1alpha1.Node, len(config.Nodes)+len(config.Edges))
+	maps.Copy(targets, config.Nodes)
+
+	for edgeName, edge := range config.Edges {
+		targets[edgePrefix+edgeName] = edgeNode(edge)
+	}
+
+	if err := s.watches.update(ctx, targets); err != nil {
 		return fmt.Errorf("failed to update watches: %w", err)
 	}
 
+	s.resources.retain(func(nodeName string) bool {
+		_, ok := targets[nodeName]
+		return ok
+	})
+
 	return nil
 }
+
+// Changes returns a channel that receives a value whenever the styling
+// changed. Multiple changes are coalesced while the channel is not
+// being read.
+func (s *Styler) Changes() <-chan struct{} {
+	return s.changes
+}
+
+func (s *Styler) notifyChange() {
+	select {
+	case s.changes <- struct{}{}:
+	default:
+		// a notification is already pending
+	}
+}
+
+// Statuses returns the current status of all configured nodes.
+// Nodes without any observed resources are absent.
+func (s *Styler) Statuses() map[string]mklv1alpha1.ResourceStatus {
+	states := s.NodeStates()
+
+	statuses := make(map[string]mklv1alpha1.ResourceStatus, len(states))
+	for nodeName, state := range states {
+		statuses[nodeName] = state.Status
+	}
+
+	return statuses
+}
+
+// NodeStates returns the current state of all configured nodes.
+// Nodes without any observed resources are absent.
+func (s *Styler) NodeStates() map[string]NodeState {
+	s.styleLock.RLock()
+	defer s.styleLock.RUnlock()
+
+	states := make(map[string]NodeState, len(s.nodes))
+	for nodeName := range s.nodes {
+		state, ok := s.states[nodeName]
+		if !ok {
+			state = NodeState{Status: mklv1alpha1.ResourceAbsent}
+		}
+
+		state.Clusters = slices.Clone(state.Clusters)
+		states[nodeName] = state
+	}
+
+	return states
+}
+
+// Unsynced returns the names of the nodes and edges whose resources
+// have not been observed in all matching clusters yet.
+// Nodes without any matching cluster are unsynced.
+func (s *Styler) Unsynced() []string {
+	return s.watches.unsynced()
+}
+
+// SyncErrors returns the errors of the nodes whose resources failed to
+// sync, e.g. because they could not be listed. These nodes stay
+// unsynced.
+func (s *Styler) SyncErrors() map[string]error {
+	return s.watches.syncErrors()
+}
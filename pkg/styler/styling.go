@@ -1,11 +1,16 @@
 package styler
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // GetStyling returns the current styles for all nodes.
@@ -15,8 +20,9 @@ func (s *Styler) GetStyling() (string, error) {
 	s.styleLock.RLock()
 	defer s.styleLock.RUnlock()
 
-	for _, styles := range s.styles {
-		for _, style := range styles {
+	// sorted to produce the same output for the same styles
+	for _, nodeName := range slices.Sorted(maps.Keys(s.styles)) {
+		for _, style := range s.styles[nodeName] {
 			ret.WriteString(style)
 		}
 	}
@@ -24,13 +30,49 @@ func (s *Styler) GetStyling() (string, error) {
 	return ret.String(), nil
 }
 
+// StyleDiagram returns the diagram with the current styling applied.
+func (s *Styler) StyleDiagram(diagram []byte) ([]byte, error) {
+	styling, err := s.GetStyling()
+	if err != nil {
+		return nil, err
+	}
+
+	diagram, linkStyling := s.edgeStyling(diagram)
+
+	ret := bytes.Buffer{}
+	ret.Write(diagram)
+	ret.WriteString("\n")
+	ret.WriteString(styling)
+	ret.WriteString(linkStyling)
+
+	return ret.Bytes(), nil
+}
+
 func (s *Styler) updateStyling(ctx context.Context, nodeName string, node mklv1alpha1.Node) error {
+	if edgeName, ok := strings.CutPrefix(nodeName, edgePrefix); ok {
+		return s.updateEdgeStyling(ctx, edgeName, node)
+	}
+
 	logger := s.Logger.WithValues("nodeName", nodeName)
 	logger.V(2).Info("updating styling for node")
 
-	resources := s.resources.get(nodeName)
+	tracked := s.resources.tracked(nodeName)
+	resources := make([]unstructured.Unstructured, len(tracked))
+	clusters := []string{}
+
+	for i, t := range tracked {
+		resources[i] = t.resource
+		if !slices.Contains(clusters, t.cluster.String()) {
+			clusters = append(clusters, t.cluster.String())
+		}
+	}
+
+	slices.Sort(clusters)
 
-	status := resourceStatus(node, resources)
+	status, err := resourceStatus(ctx, s.cel, s.style, node, resources)
+	if err != nil {
+		logger.Error(err, "failed to determine status", "expression", node.Health.Expression)
+	}
 	s.Logger.V(2).Info("updated status", "status", status, "resourceCount", len(resources))
 
 	newStyles := []string{}
@@ -38,13 +80,27 @@ func (s *Styler) updateStyling(ctx context.Context, nodeName string, node mklv1a
 	style, ok := s.style.Status[status]
 	if !ok {
 		style = status.DefaultStyle()
-		if style == "" {
-			logger.Error(nil, "unknown status and no default style available, skipping styling update", "status", status)
+	}
+
+	if style == "" {
+		// an empty style is invalid mermaid and breaks the whole
+		// diagram
+		logger.Info("no style for status, using the style of unknown", "status", status)
+
+		style, ok = s.style.Status[mklv1alpha1.ResourceUnknown]
+		if !ok {
+			style = mklv1alpha1.ResourceUnknown.DefaultStyle()
 		}
 	}
 
 	newStyles = append(newStyles, fmt.Sprintf("style %s %s\n", nodeName, style))
 
+	state := NodeState{
+		Status:    status,
+		Resources: len(resources),
+		Clusters:  clusters,
+	}
+
 	if node.Label != "" {
 		logger.V(2).Info("expanding label", "label", node.Label)
 		label, err := s.cel.expandLabel(ctx, node.Label, resources)
@@ -53,12 +109,33 @@ func (s *Styler) updateStyling(ctx context.Context, nodeName string, node mklv1a
 		} else {
 			logger.V(2).Info("expanded label", "label", node.Label, "expanded", label)
 			newStyles = append(newStyles, fmt.Sprintf("%s[%s]\n", nodeName, label))
+			state.Label = label
 		}
 	}
 
 	s.styleLock.Lock()
+	if _, ok := s.nodes[nodeName]; !ok {
+		// node was removed from the config while it was reconciled
+		s.styleLock.Unlock()
+		return nil
+	}
+
+	old, ok := s.states[nodeName]
+	changed := !slices.Equal(s.styles[nodeName], newStyles) || old.Status != status
+
+	state.LastTransitionTime = old.LastTransitionTime
+	if !ok || old.Status != status {
+		state.LastTransitionTime = time.Now()
+	}
+
 	s.styles[nodeName] = newStyles
+	s.states[nodeName] = state
 	s.styleLock.Unlock()
 
+	if changed {
+		logger.V(2).Info("styling changed")
+		s.notifyChange()
+	}
+
 	return nil
 }
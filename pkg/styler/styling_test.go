@@ -0,0 +1,149 @@
+package styler
+
+import (
+	"testing"
+
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUpdateStylingChanges(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{}
+	s.nodes = map[string]mklv1alpha1.Node{"a": node, "b": node}
+
+	require.NoError(t, s.updateStyling(t.Context(), "b", node))
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+	require.Len(t, s.Changes(), 1, "changes should be coalesced")
+	<-s.Changes()
+
+	styling, err := s.GetStyling()
+	require.NoError(t, err)
+	require.Equal(t,
+		"style a "+mklv1alpha1.ResourceAbsent.DefaultStyle()+"\n"+
+			"style b "+mklv1alpha1.ResourceAbsent.DefaultStyle()+"\n",
+		styling,
+	)
+
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+	require.Empty(t, s.Changes(), "unchanged styling should not notify")
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("resource")
+	s.resources.replace("a", "cluster", resource)
+
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+	require.Len(t, s.Changes(), 1)
+}
+
+func TestNodeStates(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{Label: `"count: " + string(size(resources))`}
+	s.nodes = map[string]mklv1alpha1.Node{"a": node, "b": node}
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("resource")
+	s.resources.replace("a", "cluster2", resource)
+	s.resources.replace("a", "cluster1", resource)
+
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+
+	states := s.NodeStates()
+	require.Len(t, states, 2)
+
+	a := states["a"]
+	require.Equal(t, mklv1alpha1.ResourceHealthy, a.Status)
+	require.Equal(t, "count: 2", a.Label)
+	require.Equal(t, 2, a.Resources)
+	require.Equal(t, []string{"cluster1", "cluster2"}, a.Clusters)
+	require.False(t, a.LastTransitionTime.IsZero())
+
+	require.Equal(t, NodeState{Status: mklv1alpha1.ResourceAbsent}, states["b"])
+
+	// The transition time is kept while the status does not change.
+	s.resources.delete("a", "cluster2", "resource", "")
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+
+	states = s.NodeStates()
+	require.Equal(t, 1, states["a"].Resources)
+	require.Equal(t, "count: 1", states["a"].Label)
+	require.Equal(t, a.LastTransitionTime, states["a"].LastTransitionTime)
+}
+
+func TestUpdateConfigRemovesNodes(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{}
+	edge := mklv1alpha1.Edge{From: "a", To: "b"}
+	s.nodes = map[string]mklv1alpha1.Node{"a": node, "b": node}
+	s.edges = map[string]mklv1alpha1.Edge{"ab": edge}
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("resource")
+	s.resources.replace("b", "cluster", resource)
+	s.resources.replace(edgePrefix+"ab", "cluster", resource)
+
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+	require.NoError(t, s.updateStyling(t.Context(), "b", node))
+	require.NoError(t, s.updateStyling(t.Context(), edgePrefix+"ab", edgeNode(edge)))
+
+	// b disappeared, e.g. a discovered resource that was deleted
+	require.NoError(t, s.UpdateConfig(t.Context(), &mklv1alpha1.Config{
+		Nodes: map[string]mklv1alpha1.Node{"a": node},
+	}))
+
+	styling, err := s.GetStyling()
+	require.NoError(t, err)
+	require.Equal(t, "style a "+mklv1alpha1.ResourceAbsent.DefaultStyle()+"\n", styling)
+	require.NotContains(t, s.states, "b")
+	require.Empty(t, s.edgeStyles)
+	require.Empty(t, s.resources.get("b"))
+	require.Empty(t, s.resources.get(edgePrefix+"ab"))
+
+	// late reconciles of removed nodes are ignored
+	require.NoError(t, s.updateStyling(t.Context(), "b", node))
+	require.NotContains(t, s.styles, "b")
+}
+
+func TestUpdateStylingCustomStatus(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(multiplexer.New(), "")
+	require.NoError(t, err)
+
+	node := mklv1alpha1.Node{Health: mklv1alpha1.Health{Expression: `"maintenance"`}}
+	s.nodes = map[string]mklv1alpha1.Node{"a": node}
+
+	resource := unstructured.Unstructured{}
+	resource.SetName("resource")
+	s.resources.replace("a", "cluster", resource)
+
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+
+	styling, err := s.GetStyling()
+	require.NoError(t, err)
+	require.Equal(t, "style a "+mklv1alpha1.ResourceUnknown.DefaultStyle()+"\n", styling)
+
+	s.style.Status = map[mklv1alpha1.ResourceStatus]string{
+		mklv1alpha1.ResourceUnknown: "fill:grey",
+	}
+	require.NoError(t, s.updateStyling(t.Context(), "a", node))
+
+	styling, err = s.GetStyling()
+	require.NoError(t, err)
+	require.Equal(t, "style a fill:grey\n", styling)
+	require.Equal(t, mklv1alpha1.ResourceStatus("maintenance"), s.NodeStates()["a"].Status)
+}
@@ -0,0 +1,171 @@
+package styler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	mctrl "sigs.k8s.io/multicluster-runtime"
+	mccontroller "sigs.k8s.io/multicluster-runtime/pkg/controller"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// syncTracker engages the controller of a node with clusters and
+// tracks whether the resources of the node have been observed in each
+// matching cluster.
+//
+// A cluster is synced once the informer of the cluster has synced and
+// all resources in the cache have been reconciled once, so the styling
+// reflects the state of the cluster at that point.
+type syncTracker struct {
+	logger     logr.Logger
+	nodeName   string
+	node       mklv1alpha1.Node
+	controller mccontroller.Controller
+	reconciler reconciler
+	predicates []predicate.TypedPredicate[client.Object]
+
+	lock sync.Mutex
+	// clusters maps the engaged clusters matching the node to whether
+	// they have been synced.
+	clusters map[multicluster.ClusterName]bool
+	// errs maps the clusters that failed to sync to the error.
+	errs map[multicluster.ClusterName]error
+}
+
+var _ multicluster.Aware = &syncTracker{}
+
+// Engage implements multicluster.Aware.
+func (t *syncTracker) Engage(ctx context.Context, clusterName multicluster.ClusterName, cl cluster.Cluster) error {
+	if err := t.controller.Engage(ctx, clusterName, cl); err != nil {
+		return err
+	}
+
+	if !t.node.Selector.MatchesCluster(clusterName.String()) {
+		return nil
+	}
+
+	t.lock.Lock()
+	t.clusters[clusterName] = false
+	t.lock.Unlock()
+
+	go func() {
+		if err := t.sync(ctx, clusterName, cl); err != nil {
+			t.logger.Error(err, "failed to sync cluster", "cluster", clusterName)
+
+			t.lock.Lock()
+			t.errs[clusterName] = err
+			t.lock.Unlock()
+
+			return
+		}
+
+		t.lock.Lock()
+		t.clusters[clusterName] = true
+		t.lock.Unlock()
+		t.logger.V(2).Info("cluster synced", "cluster", clusterName)
+	}()
+
+	return nil
+}
+
+// sync waits for the informer of the cluster to sync and reconciles
+// all resources in the cache.
+func (t *syncTracker) sync(ctx context.Context, clusterName multicluster.ClusterName, cl cluster.Cluster) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(t.node.Selector.GVK)
+
+	informer, err := cl.GetCache().GetInformer(ctx, obj)
+	if err != nil {
+		return fmt.Errorf("failed to get informer: %w", err)
+	}
+
+	if !toolscache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("informer did not sync: %w", ctx.Err())
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(t.node.Selector.GVK.GroupVersion().WithKind(t.node.Selector.GVK.Kind + "List"))
+
+	if err := cl.GetCache().List(ctx, list, client.InNamespace(t.node.Selector.Namespace)); err != nil {
+		return fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	for i := range list.Items {
+		if !t.matches(&list.Items[i]) {
+			continue
+		}
+
+		req := mctrl.Request{
+			Request: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: list.Items[i].GetNamespace(),
+					Name:      list.Items[i].GetName(),
+				},
+			},
+			ClusterName: clusterName,
+		}
+
+		if _, err := t.reconciler.Reconcile(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matches checks the object against the predicates of the watch.
+func (t *syncTracker) matches(obj client.Object) bool {
+	for _, p := range t.predicates {
+		if !p.Generic(event.TypedGenericEvent[client.Object]{Object: obj}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// synced returns true if at least one cluster matching the node was
+// engaged and all engaged clusters matching the node have synced.
+func (t *syncTracker) synced() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.clusters) == 0 {
+		return false
+	}
+
+	for _, synced := range t.clusters {
+		if !synced {
+			return false
+		}
+	}
+
+	return true
+}
+
+// err returns the errors of the clusters that failed to sync.
+func (t *syncTracker) err() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	errs := make([]error, 0, len(t.errs))
+	for _, clusterName := range slices.Sorted(maps.Keys(t.errs)) {
+		errs = append(errs, fmt.Errorf("cluster %s: %w", clusterName, t.errs[clusterName]))
+	}
+
+	return errors.Join(errs...)
+}
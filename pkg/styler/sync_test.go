@@ -0,0 +1,81 @@
+package styler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+func TestSyncTrackerSynced(t *testing.T) {
+	t.Parallel()
+
+	tracker := &syncTracker{clusters: map[multicluster.ClusterName]bool{}}
+	require.False(t, tracker.synced(), "no matching cluster engaged")
+
+	tracker.clusters["cluster1"] = true
+	tracker.clusters["cluster2"] = false
+	require.False(t, tracker.synced())
+
+	tracker.clusters["cluster2"] = true
+	require.True(t, tracker.synced())
+}
+
+func TestSyncTrackerMatches(t *testing.T) {
+	t.Parallel()
+
+	tracker := &syncTracker{
+		predicates: []predicate.TypedPredicate[client.Object]{
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == "match"
+			}),
+		},
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetName("match")
+	require.True(t, tracker.matches(obj))
+
+	obj.SetName("other")
+	require.False(t, tracker.matches(obj))
+}
+
+func TestWatchesUnsynced(t *testing.T) {
+	t.Parallel()
+
+	w := &watches{
+		trackers: map[nodeHash]*syncTracker{
+			"b": {nodeName: "b", clusters: map[multicluster.ClusterName]bool{}},
+			"a": {nodeName: "a", clusters: map[multicluster.ClusterName]bool{"cluster1": false}},
+			"c": {nodeName: "c", clusters: map[multicluster.ClusterName]bool{"cluster1": true}},
+		},
+	}
+
+	require.Equal(t, []string{"a", "b"}, w.unsynced())
+}
+
+func TestWatchesSyncErrors(t *testing.T) {
+	t.Parallel()
+
+	w := &watches{
+		trackers: map[nodeHash]*syncTracker{
+			"a": {
+				nodeName: "a",
+				clusters: map[multicluster.ClusterName]bool{"cluster1": false, "cluster2": false, "cluster3": true},
+				errs: map[multicluster.ClusterName]error{
+					"cluster2": errors.New("no matches for kind"),
+					"cluster1": errors.New("forbidden"),
+				},
+			},
+			"b": {nodeName: "b", clusters: map[multicluster.ClusterName]bool{"cluster1": false}},
+		},
+	}
+
+	errs := w.syncErrors()
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs["a"], "cluster cluster1: forbidden\ncluster cluster2: no matches for kind")
+}
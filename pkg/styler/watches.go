@@ -8,20 +8,22 @@ import (
 	"hash/fnv"
 	"maps"
 	"slices"
+	"sync"
 
 	"github.com/go-logr/logr"
-	"github.com/ntnn/mcutils"
 	mklv1alpha1 "github.com/ntnn/mermaid-kube-live/apis/v1alpha1"
 	"github.com/ntnn/mermaid-kube-live/pkg/multiplexer"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	mctrl "sigs.k8s.io/multicluster-runtime"
 	mccontroller "sigs.k8s.io/multicluster-runtime/pkg/controller"
+	mchandler "sigs.k8s.io/multicluster-runtime/pkg/handler"
 	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+	mcsource "sigs.k8s.io/multicluster-runtime/pkg/source"
 )
 
 type nodeHash string
@@ -38,20 +40,28 @@ func hashNode(nodeName string, node mklv1alpha1.Node) nodeHash {
 }
 
 type watches struct {
-	logger         logr.Logger
-	mp             *multiplexer.Multiplexer
+	logger logr.Logger
+	mp     *multiplexer.Multiplexer
+	// prefix is prepended to the names of the watches in the
+	// multiplexer so multiple stylers can share it
+	prefix         string
 	reconcilerOpts reconcilerOpts
 	// cancel functions to track the watches for each node so they can be
 	// stopped when the node is removed from the config
 	cancels map[nodeHash]context.CancelFunc
+	// trackers track the sync state of the watches for each node
+	trackersLock sync.Mutex
+	trackers     map[nodeHash]*syncTracker
 }
 
-func newWatches(mp *multiplexer.Multiplexer, reconcilerOpts reconcilerOpts) *watches {
+func newWatches(mp *multiplexer.Multiplexer, prefix string, reconcilerOpts reconcilerOpts) *watches {
 	return &watches{
 		logger:         mctrl.Log.WithName("watches"),
 		mp:             mp,
+		prefix:         prefix,
 		reconcilerOpts: reconcilerOpts,
 		cancels:        make(map[nodeHash]context.CancelFunc),
+		trackers:       make(map[nodeHash]*syncTracker),
 	}
 }
 
@@ -69,7 +79,11 @@ func (w *watches) update(ctx context.Context, nodes map[string]mklv1alpha1.Node)
 			w.logger.V(2).Info("stopping watch for node hash", "nodeHash", hash)
 			cancel()
 			delete(w.cancels, hash)
-			w.mp.DeleteAware(string(hash))
+			w.mp.DeleteAware(w.prefix + string(hash))
+
+			w.trackersLock.Lock()
+			delete(w.trackers, hash)
+			w.trackersLock.Unlock()
 		}
 	}
 
@@ -86,7 +100,7 @@ func (w *watches) update(ctx context.Context, nodes map[string]mklv1alpha1.Node)
 		ctx, cancel := context.WithCancel(ctx)
 
 		w.logger.V(2).Info("starting watch for node", "nodeName", nodeName, "nodeHash", hash)
-		c, err := w.startUnmanaged(ctx, nodeName, node)
+		tracker, err := w.startUnmanaged(ctx, nodeName, node)
 		if err != nil {
 			w.logger.Error(err, "failed to start watch for node", "nodeName", nodeName)
 			errs = fmt.Errorf("%w; failed to start watch for node %s: %w", errs, nodeName, err)
@@ -94,25 +108,66 @@ func (w *watches) update(ctx context.Context, nodes map[string]mklv1alpha1.Node)
 			continue
 		}
 
-		if err := w.mp.AddAware(ctx, string(hash), c); err != nil {
+		if err := w.mp.AddAware(ctx, w.prefix+string(hash), tracker); err != nil {
 			w.logger.Error(err, "failed to add watch for node to multiplexer", "nodeName", nodeName)
 			errs = fmt.Errorf("%w; failed to add watch for node %s to multiplexer: %w", errs, nodeName, err)
 			cancel()
+			w.mp.DeleteAware(w.prefix + string(hash))
 			continue
 		}
 
 		w.cancels[hash] = cancel
+
+		w.trackersLock.Lock()
+		w.trackers[hash] = tracker
+		w.trackersLock.Unlock()
+	}
+
+	return errs
+}
+
+// unsynced returns the names of the nodes whose watches have not synced
+// yet.
+func (w *watches) unsynced() []string {
+	w.trackersLock.Lock()
+	defer w.trackersLock.Unlock()
+
+	var names []string
+
+	for _, tracker := range w.trackers {
+		if !tracker.synced() {
+			names = append(names, tracker.nodeName)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// syncErrors returns the errors of the nodes whose watches failed to
+// sync in any cluster.
+func (w *watches) syncErrors() map[string]error {
+	w.trackersLock.Lock()
+	defer w.trackersLock.Unlock()
+
+	errs := map[string]error{}
+
+	for _, tracker := range w.trackers {
+		if err := tracker.err(); err != nil {
+			errs[tracker.nodeName] = err
+		}
 	}
 
 	return errs
 }
 
-func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv1alpha1.Node) (mccontroller.Controller, error) { //nolint:cyclop
+func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv1alpha1.Node) (*syncTracker, error) { //nolint:cyclop
 	logger := w.logger.WithValues("node", nodeName)
 	logger.V(2).Info("creating unmanaged controller",
 		"gvk", node.Selector.GVK.String(),
 		"namespace", node.Selector.Namespace,
-		"cluster", node.Selector.ClusterName,
+		"clusters", node.Selector.ClusterPatterns(),
 		"owner", node.Selector.Owner,
 	)
 
@@ -142,32 +197,50 @@ func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv
 		predicates = append(predicates, labelPredicate)
 	}
 
+	var matches func(obj client.Object) bool
+
+	if node.Selector.FieldSelector != "" {
+		logger.V(2).Info("adding field selector predicate", "fieldSelector", node.Selector.FieldSelector)
+
+		var err error
+
+		matches, err = fieldSelectorMatcher(node.Selector.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		predicates = append(predicates, transitionPredicate(matches))
+	}
+
 	if node.Selector.Owner.Name != "" {
 		logger.V(2).Info("adding owner predicate",
 			"ownerGVK", node.Selector.Owner.GVK.String(),
 			"ownerName", node.Selector.Owner.Name,
+			"ownerDepth", ownerDepth(node.Selector.Owner),
+			"allOwners", node.Selector.Owner.AllOwners,
 		)
 
 		predicates = append(predicates, predicate.NewPredicateFuncs(func(obj client.Object) bool {
-			ownerRef := metav1.GetControllerOf(obj)
-			if ownerRef == nil {
-				logger.V(3).Info("object has no controller owner", "object", obj.GetName())
+			refs := ownerRefs(node.Selector.Owner, obj)
+			if len(refs) == 0 {
+				logger.V(3).Info("object has no owner", "object", obj.GetName())
 				return false
 			}
 
-			if ownerRef.APIVersion == node.Selector.Owner.GVK.GroupVersion().String() &&
-				ownerRef.Kind == node.Selector.Owner.GVK.Kind &&
-				ownerRef.Name == node.Selector.Owner.Name {
-				logger.V(3).Info("object matches owner predicate", "object", obj.GetName())
+			if ownerDepth(node.Selector.Owner) > 1 {
+				// The ownership chain is resolved in the reconciler
+				// as it requires access to the cluster.
 				return true
 			}
 
-			logger.V(3).Info("object owner does not match",
-				"object", obj.GetName(),
-				"ownerAPIVersion", ownerRef.APIVersion,
-				"ownerKind", ownerRef.Kind,
-				"ownerName", ownerRef.Name,
-			)
+			for _, ref := range refs {
+				if ownerRefMatches(node.Selector.Owner, ref) {
+					logger.V(3).Info("object matches owner predicate", "object", obj.GetName())
+					return true
+				}
+			}
+
+			logger.V(3).Info("object owner does not match", "object", obj.GetName(), "owners", refs)
 
 			return false
 		}))
@@ -183,6 +256,7 @@ func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv
 		opts:     w.reconcilerOpts,
 		nodeName: nodeName,
 		node:     node,
+		matches:  matches,
 	}
 
 	opts := mccontroller.Options{
@@ -191,7 +265,7 @@ func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv
 		Reconciler:         r,
 	}
 
-	c, err := mcutils.UnmanagedController(nodeName, watchObj, multicluster.ClusterName(node.Selector.ClusterName), predicates, opts)
+	c, err := unmanagedController(nodeName, watchObj, node.Selector.MatchesCluster, predicates, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create unmanaged controller for node %s: %w", nodeName, err)
 	}
@@ -208,14 +282,55 @@ func (w *watches) startUnmanaged(ctx context.Context, nodeName string, node mklv
 
 	logger.Info("unmanaged controller setup complete, waiting for watches to become active")
 
+	return &syncTracker{
+		logger:     logger,
+		nodeName:   nodeName,
+		node:       node,
+		controller: c,
+		reconciler: r,
+		predicates: predicates,
+		clusters:   make(map[multicluster.ClusterName]bool),
+		errs:       make(map[multicluster.ClusterName]error),
+	}, nil
+}
+
+// unmanagedController creates an unmanaged controller watching the
+// given object in all clusters matched by clusterFilter.
+// Like mcutils.UnmanagedController but allows to match multiple
+// clusters.
+func unmanagedController(
+	name string,
+	obj client.Object,
+	clusterFilter func(clusterName string) bool,
+	predicates []predicate.TypedPredicate[client.Object],
+	opts mccontroller.Options,
+) (mccontroller.Controller, error) {
+	handler := mchandler.TypedEnqueueRequestForObject[client.Object]()
+	source := mcsource.TypedKind(obj, handler, predicates...).
+		WithClusterFilter(func(clusterName multicluster.ClusterName, _ cluster.Cluster) bool {
+			return clusterFilter(clusterName.String())
+		})
+
+	c, err := mccontroller.NewUnmanaged(name, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.MultiClusterWatch(source); err != nil {
+		return nil, fmt.Errorf("failed to start watch: %w", err)
+	}
+
 	return c, nil
 }
 
 type reconcilerOpts struct {
 	getCluster      func(ctx context.Context, name multicluster.ClusterName) (cluster.Cluster, error)
-	deleteResource  func(nodeName, name, namespace string)
-	replaceResource func(nodeName string, resource unstructured.Unstructured)
+	deleteResource  func(nodeName string, clusterName multicluster.ClusterName, name, namespace string)
+	replaceResource func(nodeName string, clusterName multicluster.ClusterName, resource unstructured.Unstructured)
 	updateStyling   func(ctx context.Context, nodeName string, node mklv1alpha1.Node) error
+	// record is called with every observed resource, obj is nil if
+	// the resource was not found
+	record func(clusterName multicluster.ClusterName, gvk schema.GroupVersionKind, namespace, name string, obj *unstructured.Unstructured)
 }
 
 type reconciler struct {
@@ -223,6 +338,9 @@ type reconciler struct {
 	opts     reconcilerOpts
 	nodeName string
 	node     mklv1alpha1.Node
+	// matches is an optional check of the selector against the current
+	// state of the resource
+	matches func(obj client.Object) bool
 }
 
 func (r reconciler) Reconcile(ctx context.Context, req mctrl.Request) (mctrl.Result, error) {
@@ -243,14 +361,39 @@ func (r reconciler) Reconcile(ctx context.Context, req mctrl.Request) (mctrl.Res
 			return mctrl.Result{}, fmt.Errorf("failed to get resource %s/%s in cluster %s: %w", req.Namespace, req.Name, req.ClusterName, err)
 		}
 
+		r.opts.record(req.ClusterName, r.node.Selector.GVK, req.Namespace, req.Name, nil)
+
 		logger.Info("resource not found, deleting from tracking")
-		r.opts.deleteResource(r.nodeName, req.Name, req.Namespace)
+		r.opts.deleteResource(r.nodeName, req.ClusterName, req.Name, req.Namespace)
+
+		return mctrl.Result{}, r.opts.updateStyling(ctx, r.nodeName, r.node)
+	}
+
+	r.opts.record(req.ClusterName, r.node.Selector.GVK, req.Namespace, req.Name, u)
+
+	if r.node.Selector.Owner.Name != "" && ownerDepth(r.node.Selector.Owner) > 1 {
+		ok, err := ownerMatches(ctx, cl.GetCache(), r.node.Selector.Owner, u)
+		if err != nil {
+			return mctrl.Result{}, fmt.Errorf("failed to resolve owners of %s/%s in cluster %s: %w", req.Namespace, req.Name, req.ClusterName, err)
+		}
+
+		if !ok {
+			logger.V(2).Info("resource is not owned by the selected owner")
+			r.opts.deleteResource(r.nodeName, req.ClusterName, req.Name, req.Namespace)
+
+			return mctrl.Result{}, r.opts.updateStyling(ctx, r.nodeName, r.node)
+		}
+	}
+
+	if r.matches != nil && !r.matches(u) {
+		logger.Info("resource does not match selector anymore, deleting from tracking")
+		r.opts.deleteResource(r.nodeName, req.ClusterName, req.Name, req.Namespace)
 
 		return mctrl.Result{}, r.opts.updateStyling(ctx, r.nodeName, r.node)
 	}
 
 	logger.V(2).Info("resource found, updating", "labels", u.GetLabels())
-	r.opts.replaceResource(r.nodeName, *u)
+	r.opts.replaceResource(r.nodeName, req.ClusterName, *u)
 
 	return mctrl.Result{}, r.opts.updateStyling(ctx, r.nodeName, r.node)
 }
@@ -1,9 +1,12 @@
 package webserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"time"
 
 	_ "embed"
 )
@@ -11,49 +14,228 @@ import (
 //go:embed serve.html
 var mainPage string
 
+//go:embed index.html
+var indexPage string
+
+// diagramStatus is the summary of a named diagram on the index.
+type diagramStatus struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Warnings int    `json:"warnings"`
+}
+
 func (s *WebServer) buildMux() *http.ServeMux {
 	mux := http.NewServeMux()
 
-	// Serve the main page
+	unnamed := func(*http.Request) (*Diagram, bool) {
+		// the unnamed diagram may not have been set yet, but is not
+		// served alongside named diagrams
+		return s.getDiagram(""), len(s.named()) == 0
+	}
+
+	named := func(r *http.Request) (*Diagram, bool) {
+		d := s.getDiagram(r.PathValue("name"))
+		return d, d != nil && d.name != ""
+	}
+
+	// Serve the main page, or the index if there are named diagrams
 	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "text/html")
-		w.WriteHeader(http.StatusOK)
+		page := mainPage
+		if len(s.named()) > 0 {
+			page = indexPage
+		}
 
-		if _, err := w.Write([]byte(mainPage)); err != nil {
-			log.Printf("failed to write response: %v", err)
+		writePage(w, page)
+	})
+
+	mux.HandleFunc("/diagram", s.diagramHandler(unnamed))
+	mux.HandleFunc("/warnings", s.warningsHandler(unnamed))
+	mux.HandleFunc("/api/v1/nodes", s.nodesHandler(unnamed))
+	mux.HandleFunc("/api/v1/nodes/{node}", s.nodesHandler(unnamed))
+	mux.HandleFunc("/events", s.eventsHandler(func(*http.Request) (*subscribers, bool) {
+		return s.subscribers, true
+	}))
+
+	// Serve the named diagrams and their status for the index
+	mux.HandleFunc("/diagrams", func(w http.ResponseWriter, _ *http.Request) {
+		statuses := []diagramStatus{}
+
+		for _, d := range s.named() {
+			d.diagramLock.RLock()
+			statuses = append(statuses, diagramStatus{
+				Name:     d.name,
+				Status:   d.status,
+				Warnings: len(d.warnings),
+			})
+			d.diagramLock.RUnlock()
+		}
+
+		writeJSON(w, statuses)
+	})
+
+	mux.HandleFunc("/d/{name}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc("/d/{name}/{$}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := named(r); !ok {
+			http.NotFound(w, r)
+			return
 		}
+
+		writePage(w, mainPage)
 	})
 
-	// Serve the built diagram
-	mux.HandleFunc("/diagram", func(w http.ResponseWriter, _ *http.Request) {
+	mux.HandleFunc("/d/{name}/diagram", s.diagramHandler(named))
+	mux.HandleFunc("/d/{name}/warnings", s.warningsHandler(named))
+	mux.HandleFunc("/d/{name}/api/v1/nodes", s.nodesHandler(named))
+	mux.HandleFunc("/d/{name}/api/v1/nodes/{node}", s.nodesHandler(named))
+	mux.HandleFunc("/d/{name}/events", s.eventsHandler(func(r *http.Request) (*subscribers, bool) {
+		d, ok := named(r)
+		if !ok {
+			return nil, false
+		}
+
+		return d.subscribers, true
+	}))
+
+	return mux
+}
+
+func writePage(w http.ResponseWriter, page string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write([]byte(page)); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// diagramHandler serves the built diagram.
+func (s *WebServer) diagramHandler(get func(*http.Request) (*Diagram, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, ok := get(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var ret []byte
+
+		if d != nil {
+			d.diagramLock.RLock()
+			ret = slices.Clone(d.diagram)
+			d.diagramLock.RUnlock()
+		}
+
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusOK)
-		s.diagramLock.RLock()
-		ret := make([]byte, len(s.diagram))
-		copy(ret, s.diagram)
-		s.diagramLock.RUnlock()
 
 		if _, err := w.Write(ret); err != nil {
 			log.Printf("failed to write response: %v", err)
 		}
-	})
+	}
+}
+
+// warningsHandler serves the warnings about the diagram.
+func (s *WebServer) warningsHandler(get func(*http.Request) (*Diagram, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, ok := get(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		warnings := []string{}
+
+		if d != nil {
+			d.diagramLock.RLock()
+			warnings = append(warnings, d.warnings...)
+			d.diagramLock.RUnlock()
+		}
+
+		writeJSON(w, warnings)
+	}
+}
+
+// nodesHandler serves the state of all nodes of the diagram, or of a
+// single node if the node path value is set.
+func (s *WebServer) nodesHandler(get func(*http.Request) (*Diagram, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, ok := get(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		nodes := []Node{}
+		if d != nil {
+			nodes = d.Nodes()
+		}
+
+		nodeName := r.PathValue("node")
+		if nodeName == "" {
+			writeJSON(w, nodes)
+			return
+		}
+
+		i := slices.IndexFunc(nodes, func(n Node) bool { return n.Name == nodeName })
+		if i < 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeJSON(w, nodes[i])
+	}
+}
+
+// eventsHandler notifies clients about updates of the subscribers.
+func (s *WebServer) eventsHandler(get func(*http.Request) (*subscribers, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, ok := get(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
 
-	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
 		w.Header().Set("Connection", "keep-alive")
 
-		defer r.Context().Done()
+		ch := subs.subscribe()
+		defer subs.unsubscribe(ch)
+
+		rc := http.NewResponseController(w)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ch:
+			}
+
+			if err := rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout)); err != nil {
+				log.Printf("failed to set write deadline: %v", err)
+			}
 
-		for range s.notifyChan {
 			if _, err := fmt.Fprintf(w, "data: diagram updated\n\n"); err != nil {
 				log.Printf("failed to write to response: %v", err)
 				return
 			}
 
-			w.(http.Flusher).Flush()
+			if err := rc.Flush(); err != nil {
+				log.Printf("failed to flush response: %v", err)
+				return
+			}
 		}
-	})
-
-	return mux
+	}
 }
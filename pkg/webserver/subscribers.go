@@ -0,0 +1,62 @@
+package webserver
+
+import "sync"
+
+// subscribers is a registry of clients subscribed to diagram updates.
+// Each client gets its own channel so every update is delivered to all
+// clients.
+type subscribers struct {
+	lock sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func newSubscribers() *subscribers {
+	return &subscribers{
+		subs: make(map[chan struct{}]struct{}),
+	}
+}
+
+// subscribe registers a new client. The returned channel already holds
+// a notification so the client renders the current diagram right away.
+func (s *subscribers) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	ch <- struct{}{}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.subs[ch] = struct{}{}
+
+	return ch
+}
+
+// unsubscribe removes a client from the registry.
+func (s *subscribers) unsubscribe(ch chan struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.subs, ch)
+}
+
+// notify notifies all clients without blocking. Clients that have not
+// yet consumed their last notification are not notified again, which
+// coalesces updates for slow clients.
+func (s *subscribers) notify() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// count returns the number of subscribed clients.
+func (s *subscribers) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.subs)
+}
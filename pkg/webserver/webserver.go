@@ -3,7 +3,10 @@ package webserver
 
 import (
 	"context"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,37 +16,202 @@ import (
 const (
 	readTimeout     = 5 * time.Minute
 	shutdownTimeout = 5 * time.Second
+	// eventWriteTimeout is the time after which clients that do not
+	// accept events are dropped.
+	eventWriteTimeout = 10 * time.Second
 )
 
 // WebServer is a web server that serves the diagram on a web page and notifies clients about diagram updates.
+//
+// The unnamed diagram is served at the root. Named diagrams are served
+// at /d/<name>/ with an index page listing them at the root.
 type WebServer struct {
 	Server *http.Server
 	Logger logr.Logger
 
-	// notifyChan is used to notify clients about diagram updates.
-	notifyChan chan struct{}
+	// subscribers are the clients to notify about updates of the
+	// unnamed diagram and of the index.
+	subscribers *subscribers
+
+	lock     sync.RWMutex
+	diagrams map[string]*Diagram
+}
+
+// Diagram is a diagram served by the WebServer.
+type Diagram struct {
+	name string
+
+	// subscribers are the clients to notify about diagram updates.
+	subscribers *subscribers
+	// index is notified about status changes, nil for the unnamed
+	// diagram.
+	index *subscribers
 
 	// diagram is the current diagram to serve.
 	diagramLock sync.RWMutex
 	diagram     []byte
+	// warnings are shown alongside the diagram, e.g. about configured
+	// nodes missing from the diagram.
+	warnings []string
+	// status is the overall status of the diagram shown on the index.
+	status string
+	// nodes returns the current state of the nodes for the API.
+	nodes func() []Node
+}
+
+// Node is the state of a node served by the API.
+type Node struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Label is the expanded label, empty if the node has no label.
+	Label string `json:"label,omitempty"`
+	// Resources is the number of resources matched by the node.
+	Resources int `json:"resources"`
+	// Clusters are the clusters of the matched resources.
+	Clusters           []string  `json:"clusters"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitzero"`
+}
+
+// Diagram returns the diagram with the name, adding it if it does not
+// exist yet. The empty name is the unnamed diagram.
+func (s *WebServer) Diagram(name string) *Diagram {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.subscribers == nil {
+		s.subscribers = newSubscribers()
+	}
+
+	if s.diagrams == nil {
+		s.diagrams = make(map[string]*Diagram)
+	}
+
+	if d, ok := s.diagrams[name]; ok {
+		return d
+	}
+
+	d := &Diagram{name: name}
+	if name == "" {
+		d.subscribers = s.subscribers
+	} else {
+		d.subscribers = newSubscribers()
+		d.index = s.subscribers
+	}
+
+	s.diagrams[name] = d
+
+	return d
+}
+
+// getDiagram returns the diagram with the name or nil.
+func (s *WebServer) getDiagram(name string) *Diagram {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.diagrams[name]
+}
+
+// named returns the named diagrams sorted by name.
+func (s *WebServer) named() []*Diagram {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var ret []*Diagram
+
+	for _, name := range slices.Sorted(maps.Keys(s.diagrams)) {
+		if name != "" {
+			ret = append(ret, s.diagrams[name])
+		}
+	}
+
+	return ret
 }
 
-// UpdateDiagram updates the diagram to serve and notifies clients about the update.
+// UpdateDiagram updates the unnamed diagram to serve and notifies clients about the update.
 func (s *WebServer) UpdateDiagram(diagram []byte) {
-	s.diagramLock.Lock()
-	s.diagram = diagram
-	s.diagramLock.Unlock()
+	s.Diagram("").Update(diagram)
+}
+
+// UpdateWarnings updates the warnings shown alongside the unnamed
+// diagram and notifies clients if they changed.
+func (s *WebServer) UpdateWarnings(warnings []string) {
+	s.Diagram("").UpdateWarnings(warnings)
+}
+
+// Update updates the diagram to serve and notifies clients about the update.
+func (d *Diagram) Update(diagram []byte) {
+	d.diagramLock.Lock()
+	d.diagram = diagram
+	d.diagramLock.Unlock()
 
-	if s.notifyChan != nil {
-		s.notifyChan <- struct{}{}
+	d.subscribers.notify()
+}
+
+// UpdateWarnings updates the warnings shown alongside the diagram and
+// notifies clients if they changed.
+func (d *Diagram) UpdateWarnings(warnings []string) {
+	d.diagramLock.Lock()
+	changed := !slices.Equal(d.warnings, warnings)
+	d.warnings = slices.Clone(warnings)
+	d.diagramLock.Unlock()
+
+	if changed {
+		d.subscribers.notify()
+
+		if d.index != nil {
+			d.index.notify()
+		}
 	}
 }
 
+// UpdateStatus updates the overall status of the diagram shown on the
+// index and notifies clients of the index if it changed.
+func (d *Diagram) UpdateStatus(status string) {
+	d.diagramLock.Lock()
+	changed := d.status != status
+	d.status = status
+	d.diagramLock.Unlock()
+
+	if changed && d.index != nil {
+		d.index.notify()
+	}
+}
+
+// SetNodes sets the function returning the current state of the nodes
+// of the diagram, which is called for each API request.
+func (d *Diagram) SetNodes(nodes func() []Node) {
+	d.diagramLock.Lock()
+	defer d.diagramLock.Unlock()
+
+	d.nodes = nodes
+}
+
+// Nodes returns the current state of the nodes of the diagram sorted by
+// name.
+func (d *Diagram) Nodes() []Node {
+	d.diagramLock.RLock()
+	nodes := d.nodes
+	d.diagramLock.RUnlock()
+
+	if nodes == nil {
+		return []Node{}
+	}
+
+	ret := nodes()
+	slices.SortFunc(ret, func(a, b Node) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return ret
+}
+
 // Start starts the web server.
 func (s *WebServer) Start(ctx context.Context, addr string) error {
-	if s.notifyChan == nil {
-		s.notifyChan = make(chan struct{}, 1)
+	s.lock.Lock()
+	if s.subscribers == nil {
+		s.subscribers = newSubscribers()
 	}
+	s.lock.Unlock()
 
 	if s.Server == nil {
 		s.Server = &http.Server{} //#nosec G112 - server is not exposed and the timeouts are default below
@@ -0,0 +1,234 @@
+package webserver
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func subscribe(ctx context.Context, t *testing.T, url string) *bufio.Reader {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req) //nolint:bodyclose // closed in cleanup
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return bufio.NewReader(resp.Body)
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "data: diagram updated\n", line)
+
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "\n", line)
+}
+
+func TestEventsFanOut(t *testing.T) {
+	t.Parallel()
+
+	s := &WebServer{subscribers: newSubscribers()}
+	srv := httptest.NewServer(s.buildMux())
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+
+	first := subscribe(ctx, t, srv.URL)
+	second := subscribe(ctx, t, srv.URL)
+
+	// Clients receive an initial event to render the current diagram.
+	readEvent(t, first)
+	readEvent(t, second)
+
+	// Updates must not block, with or without reading clients.
+	s.UpdateDiagram([]byte("flowchart TD"))
+	s.UpdateDiagram([]byte("flowchart LR"))
+
+	readEvent(t, first)
+	readEvent(t, second)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return s.subscribers.count() == 0
+	}, wait.ForeverTestTimeout, 10*time.Millisecond)
+}
+
+func TestWarnings(t *testing.T) {
+	t.Parallel()
+
+	s := &WebServer{subscribers: newSubscribers()}
+	srv := httptest.NewServer(s.buildMux())
+	t.Cleanup(srv.Close)
+
+	get := func() string {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/warnings", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return string(body)
+	}
+
+	require.JSONEq(t, `[]`, get())
+
+	s.UpdateWarnings([]string{"node missing"})
+	require.JSONEq(t, `["node missing"]`, get())
+}
+
+func TestNamedDiagrams(t *testing.T) {
+	t.Parallel()
+
+	s := &WebServer{subscribers: newSubscribers()}
+	srv := httptest.NewServer(s.buildMux())
+	t.Cleanup(srv.Close)
+
+	get := func(path string) (int, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	// Without named diagrams the root serves the diagram.
+	_, body := get("/")
+	require.Equal(t, mainPage, body)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+
+	index := subscribe(ctx, t, srv.URL)
+	readEvent(t, index)
+
+	frontend := s.Diagram("frontend")
+	frontend.Update([]byte("flowchart TD"))
+	frontend.UpdateStatus("healthy")
+	s.Diagram("backend").UpdateWarnings([]string{"node missing"})
+
+	_, body = get("/")
+	require.Equal(t, indexPage, body)
+
+	// The unnamed diagram is not served alongside named diagrams.
+	for _, path := range []string{"/diagram", "/warnings", "/api/v1/nodes", "/api/v1/nodes/secret"} {
+		code, _ := get(path)
+		require.Equal(t, http.StatusNotFound, code, path)
+	}
+
+	_, body = get("/diagrams")
+	require.JSONEq(t, `[
+		{"name": "backend", "status": "", "warnings": 1},
+		{"name": "frontend", "status": "healthy", "warnings": 0}
+	]`, body)
+
+	// Status and warning changes are delivered to the index.
+	readEvent(t, index)
+
+	code, body := get("/d/frontend/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, mainPage, body)
+
+	_, body = get("/d/frontend/diagram")
+	require.Equal(t, "flowchart TD", body)
+
+	_, body = get("/d/backend/warnings")
+	require.JSONEq(t, `["node missing"]`, body)
+
+	events := subscribe(ctx, t, srv.URL+"/d/frontend")
+	readEvent(t, events)
+
+	frontend.Update([]byte("flowchart LR"))
+	readEvent(t, events)
+
+	code, _ = get("/d/unknown/")
+	require.Equal(t, http.StatusNotFound, code)
+
+	code, _ = get("/d/unknown/diagram")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestNodesAPI(t *testing.T) {
+	t.Parallel()
+
+	s := &WebServer{subscribers: newSubscribers()}
+	srv := httptest.NewServer(s.buildMux())
+	t.Cleanup(srv.Close)
+
+	get := func(path string) (int, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	_, body := get("/api/v1/nodes")
+	require.JSONEq(t, `[]`, body)
+
+	transition := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.Diagram("").SetNodes(func() []Node {
+		return []Node{
+			{Name: "secret", Status: "absent", Clusters: []string{}},
+			{Name: "pod", Status: "healthy", Label: "pods: 2", Resources: 2, Clusters: []string{"cluster1"}, LastTransitionTime: transition},
+		}
+	})
+
+	_, body = get("/api/v1/nodes")
+	require.JSONEq(t, `[
+		{"name": "pod", "status": "healthy", "label": "pods: 2", "resources": 2, "clusters": ["cluster1"], "lastTransitionTime": "2026-01-02T03:04:05Z"},
+		{"name": "secret", "status": "absent", "resources": 0, "clusters": []}
+	]`, body)
+
+	code, body := get("/api/v1/nodes/secret")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"name": "secret", "status": "absent", "resources": 0, "clusters": []}`, body)
+
+	code, _ = get("/api/v1/nodes/missing")
+	require.Equal(t, http.StatusNotFound, code)
+
+	s.Diagram("frontend").SetNodes(func() []Node {
+		return []Node{{Name: "deployment", Status: "pending", Clusters: []string{}}}
+	})
+
+	_, body = get("/d/frontend/api/v1/nodes")
+	require.JSONEq(t, `[{"name": "deployment", "status": "pending", "resources": 0, "clusters": []}]`, body)
+
+	code, _ = get("/api/v1/nodes/secret")
+	require.Equal(t, http.StatusNotFound, code)
+
+	code, _ = get("/d/unknown/api/v1/nodes")
+	require.Equal(t, http.StatusNotFound, code)
+}